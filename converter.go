@@ -0,0 +1,20 @@
+package ioc
+
+import (
+	ioc "github.com/sakuradon99/ioc/internal"
+	"reflect"
+)
+
+// ByteSize is a size in bytes converted from values like "10MB", "512KiB" or 1024.
+type ByteSize = ioc.ByteSize
+
+// RegisterConverter registers the converter of the values injected into the type T and the pointers to T.
+// The builtin converters of time.Duration, time.Time, url.URL, net.IP and ByteSize can be replaced as well,
+// the types implementing encoding.TextUnmarshaler are converted without a converter.
+// Example: `RegisterConverter(func(property any) (Level, error) { return ParseLevel(cast.ToString(property)) })`
+// The returned restore function puts back the previous converter of T, eg. `t.Cleanup(RegisterConverter(...))` in tests.
+func RegisterConverter[T any](converter func(property any) (T, error)) (restore func()) {
+	return ioc.RegisterConverter(reflect.TypeOf((*T)(nil)).Elem(), func(property any) (any, error) {
+		return converter(property)
+	})
+}
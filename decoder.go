@@ -0,0 +1,402 @@
+package ioc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ValueDecoder decodes the content of a file into a value map.
+type ValueDecoder func(content []byte) (map[string]any, error)
+
+var (
+	decoderMu    sync.RWMutex
+	extToDecoder = map[string]ValueDecoder{
+		".json":       decodeJSON,
+		".yaml":       decodeYAML,
+		".yml":        decodeYAML,
+		".toml":       decodeTOML,
+		".properties": decodeProperties,
+		".ini":        decodeINI,
+		".env":        decodeDotEnv,
+	}
+)
+
+// RegisterValueDecoder registers the decoder for the files with the extension, eg. ".hcl",
+// the decoder of a builtin extension can be replaced as well.
+// The decoders are used by FileValueProvider.
+func RegisterValueDecoder(ext string, decoder ValueDecoder) {
+	decoderMu.Lock()
+	defer decoderMu.Unlock()
+
+	extToDecoder[strings.ToLower(ext)] = decoder
+}
+
+func getValueDecoder(file string) (ValueDecoder, error) {
+	decoderMu.RLock()
+	defer decoderMu.RUnlock()
+
+	ext := strings.ToLower(filepath.Ext(file))
+	if decoder, ok := extToDecoder[ext]; ok {
+		return decoder, nil
+	}
+
+	exts := make([]string, 0, len(extToDecoder))
+	for e := range extToDecoder {
+		exts = append(exts, e)
+	}
+	sort.Strings(exts)
+	return nil, fmt.Errorf("unsupported file format %q, only %s are supported", ext, strings.Join(exts, ", "))
+}
+
+func decodeJSON(content []byte) (map[string]any, error) {
+	valueMap := make(map[string]any)
+	err := json.Unmarshal(content, &valueMap)
+	if err != nil {
+		return nil, err
+	}
+	return valueMap, nil
+}
+
+func decodeYAML(content []byte) (map[string]any, error) {
+	valueMap := make(map[string]any)
+	err := yaml.Unmarshal(content, &valueMap)
+	if err != nil {
+		return nil, err
+	}
+	// the nested maps with non-string keys, like `1: a`, are decoded as map[any]any
+	return normalizeValue(valueMap).(map[string]any), nil
+}
+
+// yamlKeyLines returns the lines of the keys of the YAML content by their dotted keys, eg. "db.host".
+func yamlKeyLines(content []byte) map[string]int {
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil
+	}
+
+	lines := make(map[string]int)
+	var walk func(prefix string, n *yaml.Node)
+	walk = func(prefix string, n *yaml.Node) {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(prefix, c)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				k, v := n.Content[i], n.Content[i+1]
+				// the keys of the merged anchors, like `<<: *base`, are defined elsewhere
+				if k.Tag == "!!merge" {
+					continue
+				}
+				key := k.Value
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				lines[key] = k.Line
+				walk(key, v)
+			}
+		case yaml.SequenceNode:
+			// the elements are located by indexed keys, eg. "servers.[0]"
+			for i, e := range n.Content {
+				key := fmt.Sprintf("%s.[%d]", prefix, i)
+				lines[key] = e.Line
+				walk(key, e)
+			}
+		}
+	}
+	walk("", &node)
+	return lines
+}
+
+func decodeTOML(content []byte) (map[string]any, error) {
+	valueMap := make(map[string]any)
+	err := toml.Unmarshal(content, &valueMap)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeValue(valueMap).(map[string]any), nil
+}
+
+// normalizeValue converts the typed slices and maps of decoders into []any and map[string]any.
+func normalizeValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalizeValue(e)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalizeValue(e)
+		}
+		return m
+	case []map[string]any:
+		slice := make([]any, len(v))
+		for i, e := range v {
+			slice[i] = normalizeValue(e)
+		}
+		return slice
+	case []any:
+		for i, e := range v {
+			v[i] = normalizeValue(e)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// decodeProperties decodes Java-style properties, the dotted keys are expanded into nested maps,
+// and the keys with indexes like "servers.0" are converted into lists.
+func decodeProperties(content []byte) (map[string]any, error) {
+	lines, err := readLogicalLines(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	keyToValue := make(map[string]string)
+	for _, line := range lines {
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		sep := indexUnescaped(line, "=: \t")
+		key, value := line, ""
+		if sep >= 0 {
+			key = line[:sep]
+			value = strings.TrimLeft(line[sep:], " \t")
+			if value != "" && (value[0] == '=' || value[0] == ':') {
+				value = strings.TrimLeft(value[1:], " \t")
+			}
+		}
+		key = unescapeProperty(key)
+		if _, exist := keyToValue[key]; !exist {
+			keys = append(keys, key)
+		}
+		keyToValue[key] = unescapeProperty(value)
+	}
+
+	// parent keys are set before nested keys, so that nested keys win over conflicting parent values
+	sort.Strings(keys)
+	valueMap := make(map[string]any)
+	for _, key := range keys {
+		setNestedValue(valueMap, strings.Split(key, "."), keyToValue[key])
+	}
+	for k, v := range valueMap {
+		valueMap[k] = indexedMapsToSlices(v)
+	}
+	return valueMap, nil
+}
+
+// readLogicalLines trims the lines and joins the lines ending with an unescaped backslash.
+func readLogicalLines(content []byte) ([]string, error) {
+	var lines []string
+	var current strings.Builder
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if current.Len() == 0 && (strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!")) {
+			lines = append(lines, line)
+			continue
+		}
+		trailing := len(line) - len(strings.TrimRight(line, `\`))
+		if trailing%2 == 1 {
+			current.WriteString(line[:len(line)-1])
+			continue
+		}
+		current.WriteString(line)
+		lines = append(lines, current.String())
+		current.Reset()
+	}
+	if current.Len() > 0 {
+		lines = append(lines, current.String())
+	}
+	return lines, scanner.Err()
+}
+
+func indexUnescaped(s string, chars string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte(chars, s[i]) >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeProperty(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 < len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					b.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// decodeINI decodes INI files, the sections like "[db.primary]" are expanded into nested maps,
+// the keys before any section are at the top level.
+func decodeINI(content []byte) (map[string]any, error) {
+	valueMap := make(map[string]any)
+	section := valueMap
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			if line[len(line)-1] != ']' {
+				return nil, fmt.Errorf("invalid ini section at line %d: %s", lineNo, line)
+			}
+			section = valueMap
+			for _, name := range strings.Split(strings.TrimSpace(line[1:len(line)-1]), ".") {
+				next, ok := section[name].(map[string]any)
+				if !ok {
+					next = make(map[string]any)
+					section[name] = next
+				}
+				section = next
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			key, value, ok = strings.Cut(line, ":")
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid ini entry at line %d: %s", lineNo, line)
+		}
+		section[strings.TrimSpace(key)] = unquote(strings.TrimSpace(value))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return valueMap, nil
+}
+
+// decodeDotEnv decodes dotenv files, the keys are mapped like EnvValueProvider,
+// eg. `APP_DB_HOST=localhost` provides `app.db.host`, and matched relaxedly, see FileValueProvider.RelaxedKeys.
+func decodeDotEnv(content []byte) (map[string]any, error) {
+	var names []string
+	nameToValue := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid dotenv entry at line %d: %s", lineNo, line)
+		}
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if len(value) > 0 && (value[0] == '"' || value[0] == '\'') {
+			// drop anything after the closing quote, eg. inline comments
+			if end := closingQuoteIndex(value); end > 0 {
+				value = value[:end+1]
+			}
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		if _, exist := nameToValue[name]; !exist {
+			names = append(names, name)
+		}
+		nameToValue[name] = unquote(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+	valueMap := make(map[string]any)
+	for _, name := range names {
+		setNestedValue(valueMap, envNameToKeys(name, "_"), nameToValue[name])
+	}
+	for k, v := range valueMap {
+		valueMap[k] = indexedMapsToSlices(v)
+	}
+	return valueMap, nil
+}
+
+func closingQuoteIndex(value string) int {
+	quote := value[0]
+	for i := 1; i < len(value); i++ {
+		if value[i] == '\\' && quote == '"' {
+			i++
+			continue
+		}
+		if value[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote removes the quotes of the value, escapes are only processed in double quotes.
+func unquote(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+	switch {
+	case value[0] == '"' && value[len(value)-1] == '"':
+		if s, err := strconv.Unquote(value); err == nil {
+			return s
+		}
+		return value[1 : len(value)-1]
+	case value[0] == '\'' && value[len(value)-1] == '\'':
+		return value[1 : len(value)-1]
+	default:
+		return value
+	}
+}
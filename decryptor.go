@@ -0,0 +1,107 @@
+package ioc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	ioc "github.com/sakuradon99/ioc/internal"
+	"os"
+	"strings"
+)
+
+// Decryptor decrypts the ciphertext of the encrypted values like `ENC(ciphertext)`.
+type Decryptor = ioc.Decryptor
+
+// EncryptedValueName is the name of the encrypted values of AESCipher, eg. `ENC(ciphertext)`.
+const EncryptedValueName = "ENC"
+
+// RegisterDecryptor registers the decryptor of the values like `NAME(ciphertext)`,
+// the values are decrypted on lookup, so the placeholders referring to them get the decrypted values.
+// The encrypted values are masked in ExplainValue and DumpValues.
+// Example: `RegisterDecryptor(EncryptedValueName, cipher)`
+func RegisterDecryptor(name string, decryptor Decryptor) {
+	ioc.RegisterDecryptor(name, decryptor)
+}
+
+// AESCipher encrypts and decrypts the values with AES-GCM,
+// the ciphertext is the base64 encoded nonce followed by the sealed value.
+type AESCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESCipher creates the cipher of the key, the key is 16, 24 or 32 bytes for AES-128, AES-192 or AES-256.
+func NewAESCipher(key []byte) (*AESCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESCipher{aead: aead}, nil
+}
+
+// NewAESCipherFromEnv creates the cipher of the base64 encoded key of the environment variable.
+func NewAESCipherFromEnv(name string) (*AESCipher, error) {
+	encoded, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s of the key not set", name)
+	}
+	return newAESCipherFromBase64(encoded)
+}
+
+// NewAESCipherFromFile creates the cipher of the base64 encoded key of the file, eg. a mounted Kubernetes Secret.
+func NewAESCipherFromFile(file string) (*AESCipher, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return newAESCipherFromBase64(string(content))
+}
+
+func newAESCipherFromBase64(encoded string) (*AESCipher, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("decode key failed, err=%w", err)
+	}
+	return NewAESCipher(key)
+}
+
+// GenerateAESKey returns a random base64 encoded key of AES-256.
+func GenerateAESKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Encrypt returns the encrypted value to put in the files, eg. `ENC(ciphertext)`.
+func (c *AESCipher) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return fmt.Sprintf("%s(%s)", EncryptedValueName, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+func (c *AESCipher) Decrypt(ciphertext string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("ciphertext too short")
+	}
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,159 @@
+package ioc
+
+import (
+	"errors"
+	ioc "github.com/sakuradon99/ioc/internal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Value is a handle of a value always reading the latest value after the values are refreshed.
+// Inject it into a field with the value tag like the other values, eg. a field `port ioc.Value[int]`
+// tagged `value:"app.port;default=8080"`, or bind it by BindValue. Get reads the value without locking, so it can be used on hot paths.
+type Value[T any] struct {
+	state *dynamicValue[T]
+}
+
+type dynamicValue[T any] struct {
+	binding   *ioc.ValueBinding
+	value     atomic.Pointer[T]
+	mu        sync.Mutex
+	validator func(T) error
+	err       error
+	listeners []func(old T, new T)
+	// cancel removes the reload listener from the container, it is nil after Close
+	cancel func()
+}
+
+// BindValue binds a handle to the key, Close the handle when it is no longer used.
+func BindValue[T any](key string) (Value[T], error) {
+	var v Value[T]
+	err := iocContainer.BindValue(&v, key)
+	if err != nil {
+		return Value[T]{}, err
+	}
+	return v, nil
+}
+
+// BindValue implements ioc.DynamicValue, it is called when the handle is injected.
+func (v *Value[T]) BindValue(binding *ioc.ValueBinding) error {
+	state := &dynamicValue[T]{binding: binding}
+	value, err := state.load()
+	if err != nil {
+		return err
+	}
+	state.value.Store(&value)
+	state.cancel = binding.OnChange(state.reload)
+
+	v.state = state
+	return nil
+}
+
+// ValueType implements ioc.DynamicValue, it returns the type T.
+func (v *Value[T]) ValueType() reflect.Type {
+	return getRefType[T]()
+}
+
+// Get returns the latest value, the zero value is returned if the handle is not bound.
+func (v Value[T]) Get() T {
+	if v.state == nil {
+		var zero T
+		return zero
+	}
+	return *v.state.value.Load()
+}
+
+// Key returns the key of the value.
+func (v Value[T]) Key() string {
+	if v.state == nil {
+		return ""
+	}
+	return v.state.binding.Key()
+}
+
+// Err returns the error of the last reload, the last valid value is kept on errors.
+func (v Value[T]) Err() error {
+	if v.state == nil {
+		return errors.New("value not bound")
+	}
+	v.state.mu.Lock()
+	defer v.state.mu.Unlock()
+	return v.state.err
+}
+
+// Validate validates the current value and the reloaded values, an invalid reloaded value is discarded.
+func (v Value[T]) Validate(validator func(value T) error) error {
+	if v.state == nil {
+		return errors.New("value not bound")
+	}
+	v.state.mu.Lock()
+	defer v.state.mu.Unlock()
+
+	v.state.validator = validator
+	return validator(*v.state.value.Load())
+}
+
+// OnChange calls fn after the value is changed by a reload.
+func (v Value[T]) OnChange(fn func(old T, new T)) {
+	if v.state == nil {
+		return
+	}
+	v.state.mu.Lock()
+	defer v.state.mu.Unlock()
+
+	v.state.listeners = append(v.state.listeners, fn)
+}
+
+// Close stops reloading the value, Get keeps returning the last value.
+// Close the handles bound by BindValue when they are no longer used, so that the container releases them.
+func (v Value[T]) Close() {
+	if v.state == nil {
+		return
+	}
+	v.state.mu.Lock()
+	cancel := v.state.cancel
+	v.state.cancel = nil
+	v.state.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (s *dynamicValue[T]) load() (T, error) {
+	var value T
+	loaded, err := s.binding.Load(getRefType[T]())
+	if err != nil {
+		return value, err
+	}
+	if loaded != nil {
+		value = loaded.(T)
+	}
+	return value, nil
+}
+
+func (s *dynamicValue[T]) reload() {
+	s.mu.Lock()
+	value, err := s.load()
+	if err == nil && s.validator != nil {
+		err = s.validator(value)
+	}
+	s.err = err
+	if err != nil {
+		s.mu.Unlock()
+		return
+	}
+
+	old := s.value.Swap(&value)
+	listeners := make([]func(old T, new T), len(s.listeners))
+	copy(listeners, s.listeners)
+	s.mu.Unlock()
+
+	if reflect.DeepEqual(*old, value) {
+		return
+	}
+	for _, fn := range listeners {
+		fn(*old, value)
+	}
+}
@@ -3,6 +3,7 @@ module github.com/sakuradon99/ioc
 go 1.20
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/Knetic/govaluate v3.0.0+incompatible
 	github.com/spf13/cast v1.8.0
 	github.com/stretchr/testify v1.10.0
@@ -0,0 +1,97 @@
+package ioc
+
+// objectCloner copies objects and their modules with fresh states,
+// the objects sharing a constructor result still share one in the copies.
+type objectCloner struct {
+	objects map[Object]Object
+	modules map[Module]*moduleImpl
+	results map[*constructorResult]*constructorResult
+}
+
+func newObjectCloner() *objectCloner {
+	return &objectCloner{
+		objects: make(map[Object]Object),
+		modules: make(map[Module]*moduleImpl),
+		results: make(map[*constructorResult]*constructorResult),
+	}
+}
+
+func (c *objectCloner) cloneObject(object Object) Object {
+	o, ok := object.(*objectImpl)
+	if !ok {
+		return object
+	}
+
+	clone := newObject(
+		o.ObjectRef,
+		o.name,
+		o.aliases,
+		o.condition,
+		o.optional,
+		o.private,
+		o.dependencies,
+		c.cloneInstanceBuilder(o.instanceBuilder),
+	)
+	if o.module != nil {
+		clone.module = c.cloneModule(o.module)
+	}
+	c.objects[object] = clone
+	return clone
+}
+
+func (c *objectCloner) cloneInstanceBuilder(builder InstanceBuilder) InstanceBuilder {
+	b, ok := builder.(*resultFieldInstanceBuilder)
+	if !ok {
+		// the other builders are stateless
+		return builder
+	}
+
+	result, ok := c.results[b.result]
+	if !ok {
+		result = newConstructorResult(b.result.instanceBuilder)
+		c.results[b.result] = result
+	}
+	return newResultFieldInstanceBuilder(result, b.fieldIndex)
+}
+
+// cloneModule clones the whole tree of the module on the first call.
+func (c *objectCloner) cloneModule(module Module) Module {
+	if clone, ok := c.modules[module]; ok {
+		return clone
+	}
+
+	root := module
+	for root.Parent() != nil {
+		root = root.Parent()
+	}
+	c.cloneModuleTree(root, nil)
+	return c.modules[module]
+}
+
+func (c *objectCloner) cloneModuleTree(module Module, parent Module) {
+	m, ok := module.(*moduleImpl)
+	if !ok {
+		return
+	}
+
+	clone := newModule(m.name, m.condition)
+	clone.parent = parent
+	c.modules[module] = clone
+	for _, sub := range m.modules {
+		c.cloneModuleTree(sub, clone)
+		if subClone, ok := c.modules[sub]; ok {
+			clone.modules = append(clone.modules, subClone)
+		}
+	}
+}
+
+// fillModules adds the cloned objects to the cloned modules, should be called after all objects are cloned.
+func (c *objectCloner) fillModules() {
+	for module, clone := range c.modules {
+		for _, object := range module.(*moduleImpl).objects {
+			if objectClone, ok := c.objects[object]; ok {
+				clone.objects = append(clone.objects, objectClone)
+			}
+		}
+	}
+}
@@ -7,7 +7,8 @@ import (
 	"strings"
 )
 
-var exprReg = regexp.MustCompile(`#([a-zA-Z0-9_.]+)`)
+// exprReg matches the value references of conditions, eg. `#app.enabled`, `#servers[0].port` or `#labels."app.kubernetes.io/name"`.
+var exprReg = regexp.MustCompile(`#((?:[a-zA-Z0-9_]+|"(?:[^"\\]|\\.)*")(?:\.(?:[a-zA-Z0-9_]+|"(?:[^"\\]|\\.)*")|\[[0-9]+\])*)`)
 
 type ConditionExecutor interface {
 	Execute(condition string) (bool, error)
@@ -40,7 +41,10 @@ func (c *conditionExecutorImpl) Execute(condition string) (bool, error) {
 		condition = strings.Replace(condition, match, p, 1)
 	}
 
-	expression, err := govaluate.NewEvaluableExpression(condition)
+	functions := map[string]govaluate.ExpressionFunction{
+		"profile": c.profile,
+	}
+	expression, err := govaluate.NewEvaluableExpressionWithFunctions(condition, functions)
 	if err != nil {
 		return false, err
 	}
@@ -55,3 +59,33 @@ func (c *conditionExecutorImpl) Execute(condition string) (bool, error) {
 
 	return v, nil
 }
+
+// profile returns true if any of the profiles is active, a profile like "!prod" is active if "prod" is not active.
+// Example: `profile('dev', 'staging')`
+func (c *conditionExecutorImpl) profile(args ...any) (any, error) {
+	activeProfiles, err := c.valueManager.ActiveProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, arg := range args {
+		profile, ok := arg.(string)
+		if !ok {
+			return nil, fmt.Errorf("profile must be a string, got %v", arg)
+		}
+		negative := strings.HasPrefix(profile, "!")
+		if containsString(activeProfiles, strings.TrimPrefix(profile, "!")) != negative {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func containsString(slice []string, s string) bool {
+	for _, e := range slice {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+package ioc
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type BindOptions struct {
+	// Strict fails the binding if any key under the prefix is not bound to a field.
+	Strict bool
+}
+
+type BindOption func(o *BindOptions)
+
+type ConfigTag struct {
+	Tag
+}
+
+func ParseConfigTag(tag string) ConfigTag {
+	return ConfigTag{ParseTag(tag)}
+}
+
+func (t ConfigTag) Strict() bool {
+	return t.HasOption("strict")
+}
+
+type configDependency struct {
+	prefix      string
+	rtp         reflect.Type
+	options     BindOptions
+	constraints Tag
+	fullType    string
+}
+
+func newConfigDependency(tag ConfigTag, rtp reflect.Type) *configDependency {
+	return &configDependency{
+		prefix:      tag.Value(),
+		rtp:         rtp,
+		options:     BindOptions{Strict: tag.Strict()},
+		constraints: tag.Tag,
+		fullType:    generateFullType(rtp),
+	}
+}
+
+func (d *configDependency) NameExpr() string {
+	return d.prefix
+}
+
+func (d *configDependency) RType() reflect.Type {
+	return d.rtp
+}
+
+func (d *configDependency) Optional() bool {
+	return false
+}
+
+func (d *configDependency) FullType() string {
+	return d.fullType
+}
+
+func (d *configDependency) isInterface() bool {
+	return false
+}
+
+func (c *ContainerImpl) getDependencyConfig(dep *configDependency) (any, error) {
+	value, err := c.valueManager.BindConfig(dep.NameExpr(), dep.RType(), dep.options)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.valueManager.ValidateValue(dep.NameExpr(), value, dep.constraints)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// BindConfig binds the values of the prefix onto the struct type and validates the bound struct.
+func (c *ContainerImpl) BindConfig(prefix string, rtp reflect.Type, options BindOptions) (any, error) {
+	value, err := c.valueManager.BindConfig(prefix, rtp, options)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.valueManager.ValidateValue(prefix, value, Tag{})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (c *valueManagerImpl) BindConfig(prefix string, rtp reflect.Type, options BindOptions) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	property, ok, err := c.getProperty(prefix)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// the defaults and required fields are still applied to the missing prefix
+		property = map[string]any{}
+	}
+
+	var unknownKeys []string
+	value, err := c.bindConfig(prefix, property, rtp, &unknownKeys)
+	if err != nil {
+		return nil, err
+	}
+	if options.Strict && len(unknownKeys) > 0 {
+		sort.Strings(unknownKeys)
+		return nil, newUnknownConfigKeysError(prefix, unknownKeys)
+	}
+
+	return value, nil
+}
+
+// bindConfig binds the property onto the type, the structs are bound by their field names,
+// the other types are converted like the values.
+func (c *valueManagerImpl) bindConfig(key string, property any, t reflect.Type, unknownKeys *[]string) (any, error) {
+	et := t
+	if et.Kind() == reflect.Pointer {
+		et = et.Elem()
+	}
+	if _, ok := getConverter(et); ok {
+		return c.convertType(key, property, t)
+	}
+
+	switch et.Kind() {
+	case reflect.Struct:
+		pm, ok := property.(map[string]any)
+		if !ok {
+			return nil, newUnsupportedValueTypeError(key, t)
+		}
+		entity := reflect.New(et)
+		err := c.bindStruct(key, pm, entity.Elem(), unknownKeys)
+		if err != nil {
+			return nil, err
+		}
+		if t.Kind() == reflect.Pointer {
+			return entity.Interface(), nil
+		}
+		return entity.Elem().Interface(), nil
+	case reflect.Slice:
+		slice, ok := toSlice(property)
+		if !ok || t.Kind() == reflect.Pointer {
+			return c.convertType(key, property, t)
+		}
+		sliceVal := reflect.MakeSlice(et, 0, len(slice))
+		for i, e := range slice {
+			v, err := c.bindConfig(indexKey(key, i), e, et.Elem(), unknownKeys)
+			if err != nil {
+				return nil, err
+			}
+			sliceVal = reflect.Append(sliceVal, toValue(v, et.Elem()))
+		}
+		return sliceVal.Interface(), nil
+	case reflect.Map:
+		pm, ok := property.(map[string]any)
+		if !ok || t.Kind() == reflect.Pointer {
+			return c.convertType(key, property, t)
+		}
+		mapVal := reflect.MakeMapWithSize(et, len(pm))
+		for k, e := range pm {
+			mk, err := c.convertType(key, k, et.Key())
+			if err != nil {
+				return nil, err
+			}
+			v, err := c.bindConfig(joinKeys(key, quoteKey(k)), e, et.Elem(), unknownKeys)
+			if err != nil {
+				return nil, err
+			}
+			mapVal.SetMapIndex(reflect.ValueOf(mk), toValue(v, et.Elem()))
+		}
+		return mapVal.Interface(), nil
+	default:
+		return c.convertType(key, property, t)
+	}
+}
+
+// bindStruct binds the properties onto the fields of the struct and reports the keys not bound to any field.
+func (c *valueManagerImpl) bindStruct(key string, pm valueMap, v reflect.Value, unknownKeys *[]string) error {
+	matched := make(map[string]bool)
+	err := c.bindFields(key, pm, v, matched, unknownKeys)
+	if err != nil {
+		return err
+	}
+
+	for k := range pm {
+		if !matched[k] {
+			*unknownKeys = append(*unknownKeys, joinKeys(key, quoteKey(k)))
+		}
+	}
+	return nil
+}
+
+// bindFields binds the properties onto the fields of the struct, the embedded structs are bound at the same level.
+func (c *valueManagerImpl) bindFields(key string, pm valueMap, v reflect.Value, matched map[string]bool, unknownKeys *[]string) error {
+	matcher := newKeyMatcher(pm)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := newStructField(v.Field(i)).v
+
+		if f.Anonymous {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer && ft.Elem().Kind() == reflect.Struct {
+				fv.Set(reflect.New(ft.Elem()))
+				fv = fv.Elem()
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				err := c.bindFields(key, pm, fv, matched, unknownKeys)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		names, tag, ok := configFieldNames(f)
+		if !ok {
+			continue
+		}
+
+		var property any
+		propertyKey := names[0]
+		for _, name := range names {
+			if k, ok := matcher.Key(name); ok {
+				matched[k] = true
+				property, propertyKey = pm[k], k
+				break
+			}
+		}
+		fieldKey := joinKeys(key, propertyKey)
+		if property == nil {
+			if defaultValue, ok := tag.Default(); ok {
+				var err error
+				property, err = c.resolvePlaceholders(defaultValue)
+				if err != nil {
+					return err
+				}
+			} else if tag.Required() {
+				return newMissingValueError(fieldKey)
+			} else {
+				continue
+			}
+		}
+
+		value, err := c.bindConfig(fieldKey, property, f.Type, unknownKeys)
+		if err != nil {
+			return err
+		}
+		if value != nil {
+			fv.Set(reflect.ValueOf(value))
+		}
+	}
+
+	return nil
+}
+
+// configFieldNames returns the key of the property tag of the field,
+// or the name of the exported field followed by the names of its yaml and json tags as fallbacks.
+// The field is skipped if it is unexported without the property tag or tagged by `yaml:"-"` or `json:"-"`.
+func configFieldNames(f reflect.StructField) ([]string, PropertyTag, bool) {
+	if tagExpr, ok := f.Tag.Lookup(TagPropertyKey); ok {
+		tag := ParsePropertyTag(tagExpr)
+		return []string{tag.Value()}, tag, true
+	}
+	if !f.IsExported() {
+		return nil, PropertyTag{}, false
+	}
+
+	names := []string{f.Name}
+	for _, key := range []string{"yaml", "json"} {
+		name, _, _ := strings.Cut(f.Tag.Get(key), ",")
+		if name == "-" {
+			return nil, PropertyTag{}, false
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, PropertyTag{}, true
+}
+
+// toValue returns the value of the converted value, or the zero value of the type if it is nil.
+func toValue(v any, t reflect.Type) reflect.Value {
+	if v == nil {
+		return reflect.Zero(t)
+	}
+	return reflect.ValueOf(v)
+}
@@ -0,0 +1,166 @@
+package ioc
+
+import (
+	"encoding"
+	"fmt"
+	"github.com/spf13/cast"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Converter converts a property, eg. a string or a number of a file, to a value of the registered type.
+type Converter func(property any) (any, error)
+
+// ByteSize is a size in bytes converted from values like "10MB", "512KiB" or 1024.
+// The units KB, MB, GB and TB are powers of 1000, the units KiB, MiB, GiB, TiB and the short units K, M, G, T
+// are powers of 1024. The units are case-insensitive.
+type ByteSize int64
+
+var (
+	converterMu     sync.RWMutex
+	typeToConverter = map[reflect.Type]Converter{
+		reflect.TypeOf(time.Duration(0)): convertDuration,
+		reflect.TypeOf(time.Time{}):      convertTime,
+		reflect.TypeOf(url.URL{}):        convertURL,
+		reflect.TypeOf(net.IP{}):         convertIP,
+		reflect.TypeOf(ByteSize(0)):      convertByteSize,
+	}
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// RegisterConverter registers the converter for the type, the converter of a builtin type can be replaced as well.
+// The pointers to the type are converted by the converter as well.
+// The returned restore function puts back the previous converter of the type, or removes the converter if there was none.
+func RegisterConverter(rtp reflect.Type, converter Converter) (restore func()) {
+	converterMu.Lock()
+	defer converterMu.Unlock()
+
+	previous, existed := typeToConverter[rtp]
+	typeToConverter[rtp] = converter
+	return func() {
+		converterMu.Lock()
+		defer converterMu.Unlock()
+
+		if existed {
+			typeToConverter[rtp] = previous
+		} else {
+			delete(typeToConverter, rtp)
+		}
+	}
+}
+
+// getConverter returns the registered converter of the type,
+// or a converter of encoding.TextUnmarshaler if the pointer to the type implements it.
+func getConverter(rtp reflect.Type) (Converter, bool) {
+	converterMu.RLock()
+	converter, ok := typeToConverter[rtp]
+	converterMu.RUnlock()
+	if ok {
+		return converter, true
+	}
+
+	if reflect.PointerTo(rtp).Implements(textUnmarshalerType) {
+		return func(property any) (any, error) {
+			text, err := cast.ToStringE(property)
+			if err != nil {
+				return nil, err
+			}
+			value := reflect.New(rtp)
+			err = value.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+			if err != nil {
+				return nil, err
+			}
+			return value.Elem().Interface(), nil
+		}, true
+	}
+
+	return nil, false
+}
+
+// convertDuration converts a duration like "1m30s", a number is a number of nanoseconds.
+func convertDuration(property any) (any, error) {
+	return cast.ToDurationE(property)
+}
+
+// convertTime converts a time in RFC 3339 or the other common formats, a number is a Unix time in seconds.
+func convertTime(property any) (any, error) {
+	return cast.ToTimeE(property)
+}
+
+func convertURL(property any) (any, error) {
+	s, err := cast.ToStringE(property)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return *u, nil
+}
+
+func convertIP(property any) (any, error) {
+	s, err := cast.ToStringE(property)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(strings.TrimSpace(s))
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", s)
+	}
+	return ip, nil
+}
+
+var byteSizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"K":   1 << 10,
+	"M":   1 << 20,
+	"G":   1 << 30,
+	"T":   1 << 40,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// convertByteSize converts a size like "10MB" or "1.5GiB", a number is a number of bytes.
+func convertByteSize(property any) (any, error) {
+	s, ok := property.(string)
+	if !ok {
+		size, err := cast.ToInt64E(property)
+		if err != nil {
+			return nil, err
+		}
+		return ByteSize(size), nil
+	}
+
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsLetter(r)
+	})
+	number, unit := s, ""
+	if i >= 0 {
+		number, unit = strings.TrimSpace(s[:i]), s[i:]
+	}
+	multiplier, ok := byteSizeUnits[strings.ToUpper(unit)]
+	if !ok {
+		return nil, fmt.Errorf("invalid byte size %q, unknown unit %q", s, unit)
+	}
+	size, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid byte size %q", s)
+	}
+	return ByteSize(size * float64(multiplier)), nil
+}
@@ -6,11 +6,36 @@ import (
 )
 
 type Container interface {
-	Register(rtp reflect.Type, opts ...RegisterOption) error
+	Register(rtp reflect.Type, opts ...RegisterOption) (*Registration, error)
+	Provide(constructor any, opts ...RegisterOption) (*Registration, error)
+	Module(name string, registrations []*Registration, modules []Module, opts ...ModuleOption) (Module, error)
+	// OpenPrefix is called when the prefix of a module is created, before the items of the module are registered.
+	OpenPrefix()
+	Unregister(rtp reflect.Type, name string) error
+	ListObjects() ([]Object, error)
+	ListOverrides() []Override
+	// Clone returns an isolated copy of the container with the registered objects and value providers,
+	// the objects of the copy are built independently.
+	Clone() Container
 	GetObject(nameExpr string, rtp reflect.Type) (any, error)
 	GetObjectList(nameExpr string, rtp reflect.Type) ([]any, error)
 	GetObjectMap(nameExpr string, rtp reflect.Type) (map[string]any, error)
-	AddValueProvider(provider ValueProvider)
+	AddValueProvider(provider ValueProvider, options ProviderOptions)
+	SetProfiles(profiles []string)
+	RefreshValues() error
+	OnValueChange(pattern string, fn ValueChangeListener) (cancel func())
+	WatchValues() (stop func())
+	BindValue(handle DynamicValue, keyExpr string) error
+	ValidateValues() error
+	SetMergeMode(mode MergeMode, listStrategy ListStrategy)
+	BindConfig(prefix string, rtp reflect.Type, options BindOptions) (any, error)
+	SetValue(key string, value any) error
+	WithValues(values map[string]any) (restore func(), err error)
+	ProviderStatuses() []ProviderStatus
+	ExplainValue(key string) (*ValueInfo, error)
+	DumpValues() ([]*ValueInfo, error)
+	AddMaskPatterns(patterns ...string)
+	ActiveProfiles() ([]string, error)
 	GetValue(keyExpr string, rtp reflect.Type) (any, bool, error)
 }
 
@@ -18,7 +43,13 @@ type ContainerImpl struct {
 	objectBuilderFactory ObjectBuilderFactory
 	objectManager        ObjectManager
 	valueManager         ValueManager
-	mu                   sync.Mutex
+	overrides            []Override
+	// openPrefixes counts the prefixes of the modules not grouped yet,
+	// the duplicate names are deferred until the prefixes are applied
+	openPrefixes int
+	// mu guards the objects, the value methods never lock it as the value manager is synchronized itself,
+	// so the values can be read and changed by the constructors, the listeners and the watchers
+	mu sync.Mutex
 }
 
 func NewContainerImpl() *ContainerImpl {
@@ -32,9 +63,9 @@ func NewContainerImpl() *ContainerImpl {
 	}
 }
 
-func (c *ContainerImpl) Register(rtp reflect.Type, opts ...RegisterOption) error {
+func (c *ContainerImpl) Register(rtp reflect.Type, opts ...RegisterOption) (*Registration, error) {
 	if rtp.Kind() != reflect.Struct {
-		return newUnsupportedRegisterType(rtp)
+		return nil, newUnsupportedRegisterType(rtp)
 	}
 
 	c.mu.Lock()
@@ -45,20 +76,189 @@ func (c *ContainerImpl) Register(rtp reflect.Type, opts ...RegisterOption) error
 		opt(&options)
 	}
 
+	return c.register(rtp, options)
+}
+
+func (c *ContainerImpl) Provide(constructor any, opts ...RegisterOption) (*Registration, error) {
+	ct := reflect.TypeOf(constructor)
+	if ct.Kind() != reflect.Func || ct.NumOut() == 0 {
+		return nil, newUnsupportedConstructorError(constructor)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var options RegisterOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	options.Constructor = constructor
+
+	if !isResultType(ct.Out(0)) {
+		if ct.Out(0).Kind() != reflect.Ptr || ct.Out(0).Elem().Kind() != reflect.Struct {
+			return nil, newUnsupportedConstructorError(constructor)
+		}
+		return c.register(ct.Out(0).Elem(), options)
+	}
+
+	objects, err := c.objectBuilderFactory.GetResultBuilder().Build(options)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, object := range objects {
+		err = c.addObject(object, options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newRegistration(objects...), nil
+}
+
+func (c *ContainerImpl) register(rtp reflect.Type, options RegisterOptions) (*Registration, error) {
 	ob := c.objectBuilderFactory.GetBuilder(options)
 	object, err := ob.Build(rtp, options)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	err = c.addObject(object, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRegistration(object), nil
+}
+
+func (c *ContainerImpl) addObject(object Object, options RegisterOptions) error {
+	if !options.Replace {
+		return c.objectManager.AddObject(object, c.openPrefixes > 0)
 	}
 
-	err = c.objectManager.AddObject(object)
+	rtp := options.ReplaceType
+	if rtp == nil {
+		rtp = object.RType()
+	}
+	old, err := c.objectManager.ReplaceObject(object, rtp)
 	if err != nil {
 		return err
 	}
+	// the replacement takes the place of the old object in its module
+	if old.Module() != nil {
+		old.Module().replaceObject(old, object)
+	}
+	c.overrides = append(c.overrides, Override{Old: old, New: object})
+	return nil
+}
 
+func (c *ContainerImpl) Unregister(rtp reflect.Type, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed, err := c.objectManager.RemoveObjects(rtp, name)
+	if err != nil {
+		return err
+	}
+	for _, old := range removed {
+		if old.Module() != nil {
+			old.Module().replaceObject(old, nil)
+		}
+		c.overrides = append(c.overrides, Override{Old: old})
+	}
 	return nil
 }
 
+func (c *ContainerImpl) ListOverrides() []Override {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Override(nil), c.overrides...)
+}
+
+func (c *ContainerImpl) Module(name string, registrations []*Registration, modules []Module, opts ...ModuleOption) (Module, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var options ModuleOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.Prefix != "" && c.openPrefixes > 0 {
+		c.openPrefixes--
+	}
+
+	module := newModule(name, options.ConditionExpr)
+	for _, sub := range modules {
+		if sub.Parent() != nil {
+			return nil, newModuleAlreadyGroupedError(sub.Path())
+		}
+		module.addModule(sub)
+	}
+	for _, registration := range registrations {
+		for _, object := range registration.Objects() {
+			if object.Module() != nil {
+				return nil, newModuleAlreadyGroupedError(generateObjectFullName(object))
+			}
+			module.addObject(object)
+		}
+	}
+
+	if options.Prefix != "" {
+		for _, object := range module.Objects() {
+			// unnamed objects keep the empty name, so that they can still be injected by type
+			name := object.Name()
+			if name != "" {
+				name = options.Prefix + name
+			}
+			aliases := make([]string, len(object.Aliases()))
+			for i, alias := range object.Aliases() {
+				aliases[i] = options.Prefix + alias
+			}
+			c.objectManager.RenameObject(object, name, aliases)
+		}
+	}
+
+	// the names are final unless the module is an item of another prefixed module
+	if c.openPrefixes == 0 {
+		err := c.objectManager.CheckDuplicates()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return module, nil
+}
+
+func (c *ContainerImpl) OpenPrefix() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.openPrefixes++
+}
+
+func (c *ContainerImpl) ListObjects() ([]Object, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.objectManager.ListObjects()
+}
+
+func (c *ContainerImpl) Clone() Container {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	valueManager := c.valueManager.Clone()
+	conditionExecutor := newConditionExecutorImpl(valueManager)
+	return &ContainerImpl{
+		objectBuilderFactory: c.objectBuilderFactory,
+		objectManager:        c.objectManager.Clone(conditionExecutor),
+		valueManager:         valueManager,
+		overrides:            append([]Override(nil), c.overrides...),
+	}
+}
+
 func (c *ContainerImpl) GetObject(nameExpr string, rtp reflect.Type) (any, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -73,7 +273,7 @@ func (c *ContainerImpl) GetObject(nameExpr string, rtp reflect.Type) (any, error
 		return nil, err
 	}
 
-	object, err := c.objectManager.GetObject(objRef.RType(), nameExpr)
+	object, err := c.objectManager.GetObject(nil, objRef.RType(), nameExpr)
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +298,7 @@ func (c *ContainerImpl) GetObjectList(nameExpr string, rtp reflect.Type) ([]any,
 		return nil, err
 	}
 
-	objects, err := c.objectManager.GetObjects(objRef.RType(), nameExpr)
+	objects, err := c.objectManager.GetObjects(nil, objRef.RType(), nameExpr)
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +328,7 @@ func (c *ContainerImpl) GetObjectMap(nameExpr string, rtp reflect.Type) (map[str
 		return nil, err
 	}
 
-	objects, err := c.objectManager.GetObjects(objRef.RType(), nameExpr)
+	objects, err := c.objectManager.GetObjects(nil, objRef.RType(), nameExpr)
 	if err != nil {
 		return nil, err
 	}
@@ -151,17 +351,60 @@ func (c *ContainerImpl) GetObjectMap(nameExpr string, rtp reflect.Type) (map[str
 	return nameToObject, nil
 }
 
-func (c *ContainerImpl) AddValueProvider(provider ValueProvider) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+func (c *ContainerImpl) AddValueProvider(provider ValueProvider, options ProviderOptions) {
+	c.valueManager.AddValueProvider(provider, options)
+}
 
-	c.valueManager.AddValueProvider(provider)
+func (c *ContainerImpl) SetProfiles(profiles []string) {
+	c.valueManager.SetProfiles(profiles)
 }
 
-func (c *ContainerImpl) GetValue(keyExpr string, rtp reflect.Type) (any, bool, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// RefreshValues reloads the values, the listeners can get objects and values.
+func (c *ContainerImpl) RefreshValues() error {
+	return c.valueManager.Refresh()
+}
 
+func (c *ContainerImpl) OnValueChange(pattern string, fn ValueChangeListener) (cancel func()) {
+	return c.valueManager.OnValueChange(pattern, fn)
+}
+
+func (c *ContainerImpl) WatchValues() (stop func()) {
+	return c.valueManager.Watch()
+}
+
+func (c *ContainerImpl) SetMergeMode(mode MergeMode, listStrategy ListStrategy) {
+	c.valueManager.SetMergeMode(mode, listStrategy)
+}
+
+func (c *ContainerImpl) SetValue(key string, value any) error {
+	return c.valueManager.SetValue(key, value)
+}
+
+func (c *ContainerImpl) WithValues(values map[string]any) (restore func(), err error) {
+	return c.valueManager.WithValues(values)
+}
+
+func (c *ContainerImpl) ProviderStatuses() []ProviderStatus {
+	return c.valueManager.ProviderStatuses()
+}
+
+func (c *ContainerImpl) ExplainValue(key string) (*ValueInfo, error) {
+	return c.valueManager.ExplainValue(key)
+}
+
+func (c *ContainerImpl) DumpValues() ([]*ValueInfo, error) {
+	return c.valueManager.DumpValues()
+}
+
+func (c *ContainerImpl) AddMaskPatterns(patterns ...string) {
+	c.valueManager.AddMaskPatterns(patterns...)
+}
+
+func (c *ContainerImpl) ActiveProfiles() ([]string, error) {
+	return c.valueManager.ActiveProfiles()
+}
+
+func (c *ContainerImpl) GetValue(keyExpr string, rtp reflect.Type) (any, bool, error) {
 	value, ok, err := c.valueManager.GetValueWithType(keyExpr, rtp)
 	if err != nil {
 		return nil, false, err
@@ -192,11 +435,17 @@ func (c *ContainerImpl) load() error {
 	return nil
 }
 
-func (c *ContainerImpl) initObject(object Object) error {
+func (c *ContainerImpl) initObject(object Object) (err error) {
 	if object.Status() == ObjectStatusInitializing {
 		return newCircularDependencyError()
 	}
 	object.StartInitialization()
+	defer func() {
+		// the failed object can be initialized again, eg. after the values are fixed
+		if err != nil {
+			object.AbortInitialization()
+		}
+	}()
 
 	var args []any
 
@@ -205,13 +454,15 @@ func (c *ContainerImpl) initObject(object Object) error {
 		var err error
 		switch dependency.(type) {
 		case *objectDependency:
-			arg, err = c.getDependencyObject(dependency.(*objectDependency))
+			arg, err = c.getDependencyObject(object.Module(), dependency.(*objectDependency))
 		case *objectListDependency:
-			arg, err = c.getDependencyObjectList(dependency.(*objectListDependency))
+			arg, err = c.getDependencyObjectList(object.Module(), dependency.(*objectListDependency))
 		case *objectMapDependency:
-			arg, err = c.getDependencyObjectMap(dependency.(*objectMapDependency))
+			arg, err = c.getDependencyObjectMap(object.Module(), dependency.(*objectMapDependency))
 		case *valueDependency:
 			arg, err = c.getDependencyValue(dependency.(*valueDependency))
+		case *configDependency:
+			arg, err = c.getDependencyConfig(dependency.(*configDependency))
 		default:
 			return newUnsupportedDependencyType(dependency)
 		}
@@ -226,7 +477,7 @@ func (c *ContainerImpl) initObject(object Object) error {
 		return err
 	}
 
-	err = processObjectInitializing(instance)
+	err = processObjectInitializing(object, instance)
 	if err != nil {
 		return err
 	}
@@ -234,8 +485,8 @@ func (c *ContainerImpl) initObject(object Object) error {
 	return nil
 }
 
-func (c *ContainerImpl) getDependencyObject(dep *objectDependency) (any, error) {
-	dependencyObject, err := c.objectManager.GetObject(dep.RType(), dep.NameExpr())
+func (c *ContainerImpl) getDependencyObject(scope Module, dep *objectDependency) (any, error) {
+	dependencyObject, err := c.objectManager.GetObject(scope, dep.RType(), dep.NameExpr())
 	if err != nil {
 		return nil, err
 	}
@@ -257,8 +508,8 @@ func (c *ContainerImpl) getDependencyObject(dep *objectDependency) (any, error)
 	return dependencyObject.Instance(), nil
 }
 
-func (c *ContainerImpl) getDependencyObjectList(dep *objectListDependency) (any, error) {
-	implObjects, err := c.objectManager.GetObjects(dep.RType(), dep.NameExpr())
+func (c *ContainerImpl) getDependencyObjectList(scope Module, dep *objectListDependency) (any, error) {
+	implObjects, err := c.objectManager.GetObjects(scope, dep.RType(), dep.NameExpr())
 	if err != nil {
 		return nil, err
 	}
@@ -284,8 +535,8 @@ func (c *ContainerImpl) getDependencyObjectList(dep *objectListDependency) (any,
 	return objectList.Interface(), nil
 }
 
-func (c *ContainerImpl) getDependencyObjectMap(dep *objectMapDependency) (any, error) {
-	implObjects, err := c.objectManager.GetObjects(dep.RType(), dep.NameExpr())
+func (c *ContainerImpl) getDependencyObjectMap(scope Module, dep *objectMapDependency) (any, error) {
+	implObjects, err := c.objectManager.GetObjects(scope, dep.RType(), dep.NameExpr())
 	if err != nil {
 		return nil, err
 	}
@@ -313,16 +564,36 @@ func (c *ContainerImpl) getDependencyObjectMap(dep *objectMapDependency) (any, e
 	return objectMap.Interface(), nil
 }
 
-func (c *ContainerImpl) getDependencyValue(dep Dependency) (any, error) {
+func (c *ContainerImpl) getDependencyValue(dep *valueDependency) (any, error) {
+	if isDynamicValueType(dep.RType()) {
+		return c.getDynamicValue(dep)
+	}
+
 	value, ok, err := c.valueManager.GetValueWithType(dep.NameExpr(), dep.RType())
 	if err != nil {
 		return nil, err
 	}
-
-	if !ok && !dep.Optional() {
-		return nil, newMissingValueError(dep.NameExpr())
+	if !ok {
+		if defaultValue, hasDefault := dep.Default(); hasDefault {
+			value, err = c.valueManager.ConvertValue(dep.NameExpr(), defaultValue, dep.RType())
+			if err != nil {
+				return nil, err
+			}
+		} else if !dep.Optional() {
+			return nil, newMissingValueError(dep.NameExpr())
+		} else if dep.RType().Kind() == reflect.Struct {
+			// the property defaults of the optional struct are applied, and its required properties are still required
+			value, err = c.valueManager.ConvertValue(dep.NameExpr(), map[string]any{}, dep.RType())
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
+	err = c.valueManager.ValidateValue(dep.NameExpr(), value, dep.constraints)
+	if err != nil {
+		return nil, err
+	}
 	return value, nil
 }
 
@@ -0,0 +1,49 @@
+package ioc
+
+import (
+	"regexp"
+	"sync"
+)
+
+// Decryptor decrypts the encrypted values like `ENC(ciphertext)`, the ciphertext between the parentheses is passed.
+type Decryptor interface {
+	Decrypt(ciphertext string) (string, error)
+}
+
+var (
+	decryptorMu     sync.RWMutex
+	nameToDecryptor = make(map[string]Decryptor)
+)
+
+// encryptedValueReg matches the encrypted values like `ENC(ciphertext)`, the name is the name of the decryptor.
+var encryptedValueReg = regexp.MustCompile(`^([A-Z][A-Z0-9_]*)\((.*)\)$`)
+
+// RegisterDecryptor registers the decryptor of the values like `NAME(ciphertext)`, eg. "ENC" for `ENC(...)`,
+// the decryptor is removed if nil.
+func RegisterDecryptor(name string, decryptor Decryptor) {
+	decryptorMu.Lock()
+	defer decryptorMu.Unlock()
+
+	if decryptor == nil {
+		delete(nameToDecryptor, name)
+		return
+	}
+	nameToDecryptor[name] = decryptor
+}
+
+// getDecryptor returns the decryptor and the ciphertext of the encrypted value, the values of unregistered names are not encrypted.
+func getDecryptor(value string) (Decryptor, string, bool) {
+	matches := encryptedValueReg.FindStringSubmatch(value)
+	if matches == nil {
+		return nil, "", false
+	}
+
+	decryptorMu.RLock()
+	defer decryptorMu.RUnlock()
+
+	decryptor, ok := nameToDecryptor[matches[1]]
+	if !ok {
+		return nil, "", false
+	}
+	return decryptor, matches[2], true
+}
@@ -11,10 +11,13 @@ type Dependency interface {
 }
 
 type valueDependency struct {
-	keyExpr  string
-	rtp      reflect.Type
-	optional bool
-	fullType string
+	keyExpr      string
+	rtp          reflect.Type
+	optional     bool
+	defaultValue *string
+	// constraints are the tag options validating the value, eg. `min=1`
+	constraints Tag
+	fullType    string
 }
 
 func newValueDependency(keyExpr string, rtp reflect.Type, optional bool) *valueDependency {
@@ -26,6 +29,15 @@ func newValueDependency(keyExpr string, rtp reflect.Type, optional bool) *valueD
 	}
 }
 
+func newValueDependencyWithTag(tag ValueTag, rtp reflect.Type) *valueDependency {
+	dependency := newValueDependency(tag.Value(), rtp, tag.Optional())
+	if defaultValue, ok := tag.Default(); ok {
+		dependency.defaultValue = &defaultValue
+	}
+	dependency.constraints = tag.Tag
+	return dependency
+}
+
 func (d *valueDependency) NameExpr() string {
 	return d.keyExpr
 }
@@ -38,6 +50,14 @@ func (d *valueDependency) Optional() bool {
 	return d.optional
 }
 
+// Default returns the default value used if the value is missing.
+func (d *valueDependency) Default() (string, bool) {
+	if d.defaultValue == nil {
+		return "", false
+	}
+	return *d.defaultValue, true
+}
+
 func (d *valueDependency) FullType() string {
 	return d.fullType
 }
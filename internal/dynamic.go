@@ -0,0 +1,94 @@
+package ioc
+
+import (
+	"reflect"
+)
+
+// DynamicValue is implemented by the pointers of the value handles reading the latest value of a key, eg. ioc.Value[T].
+// The handles are bound when injected into the fields with the value tag.
+type DynamicValue interface {
+	BindValue(binding *ValueBinding) error
+	// ValueType returns the type of the value of the handle.
+	ValueType() reflect.Type
+}
+
+var dynamicValueType = reflect.TypeOf((*DynamicValue)(nil)).Elem()
+
+// isDynamicValueType returns true if the type or the type it points to is a DynamicValue handle.
+func isDynamicValueType(rtp reflect.Type) bool {
+	if rtp.Kind() == reflect.Pointer {
+		rtp = rtp.Elem()
+	}
+	return rtp.Kind() == reflect.Struct && reflect.PointerTo(rtp).Implements(dynamicValueType)
+}
+
+// ValueBinding binds a DynamicValue to a key of the values.
+type ValueBinding struct {
+	valueManager ValueManager
+	dependency   *valueDependency
+}
+
+func newValueBinding(valueManager ValueManager, dependency *valueDependency) *ValueBinding {
+	return &ValueBinding{valueManager: valueManager, dependency: dependency}
+}
+
+func (b *ValueBinding) Key() string {
+	return b.dependency.NameExpr()
+}
+
+// Load returns the current value of the key converted to the type, or the default value if the key is missing.
+// The value is validated by the constraints of the tag.
+// It returns nil if the key is missing and optional.
+func (b *ValueBinding) Load(rtp reflect.Type) (any, error) {
+	value, ok, err := b.valueManager.GetValueWithType(b.Key(), rtp)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if defaultValue, hasDefault := b.dependency.Default(); hasDefault {
+			value, err = b.valueManager.ConvertValue(b.Key(), defaultValue, rtp)
+			if err != nil {
+				return nil, err
+			}
+		} else if !b.dependency.Optional() {
+			return nil, newMissingValueError(b.Key())
+		}
+	}
+
+	err = b.valueManager.ValidateValue(b.Key(), value, b.dependency.constraints)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// OnChange calls fn once per refresh changing the value of the key or any nested key.
+func (b *ValueBinding) OnChange(fn func()) (cancel func()) {
+	return b.valueManager.OnValueChanges(b.Key()+".**", func([]ValueChange) {
+		fn()
+	})
+}
+
+func (c *ContainerImpl) getDynamicValue(dep *valueDependency) (any, error) {
+	rtp := dep.RType()
+	isPtr := rtp.Kind() == reflect.Pointer
+	if isPtr {
+		rtp = rtp.Elem()
+	}
+
+	handle := reflect.New(rtp)
+	err := handle.Interface().(DynamicValue).BindValue(newValueBinding(c.valueManager, dep))
+	if err != nil {
+		return nil, err
+	}
+
+	if isPtr {
+		return handle.Interface(), nil
+	}
+	return handle.Elem().Interface(), nil
+}
+
+// BindValue binds the handle to the key of the values.
+func (c *ContainerImpl) BindValue(handle DynamicValue, keyExpr string) error {
+	return handle.BindValue(newValueBinding(c.valueManager, newValueDependency(keyExpr, reflect.TypeOf(handle), false)))
+}
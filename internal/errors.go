@@ -1,8 +1,10 @@
 package ioc
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 type unsupportedRegisterType struct {
@@ -18,16 +20,48 @@ func (u *unsupportedRegisterType) Error() string {
 }
 
 type objectDuplicateRegisterError struct {
-	fullType string
-	nameExpr string
+	object Object
 }
 
-func newObjectDuplicateRegisterError(fullType string, nameExpr string) *objectDuplicateRegisterError {
-	return &objectDuplicateRegisterError{fullType: fullType, nameExpr: nameExpr}
+func newObjectDuplicateRegisterError(object Object) *objectDuplicateRegisterError {
+	return &objectDuplicateRegisterError{object: object}
 }
 
 func (o *objectDuplicateRegisterError) Error() string {
-	return fmt.Sprintf("object <%s> already duplcate register", generateFullName(o.fullType, o.nameExpr))
+	return fmt.Sprintf("object <%s> already duplcate register", generateObjectFullName(o.object))
+}
+
+type missingReplaceTargetError struct {
+	fullType string
+	name     string
+}
+
+func newMissingReplaceTargetError(fullType string, name string) *missingReplaceTargetError {
+	return &missingReplaceTargetError{fullType: fullType, name: name}
+}
+
+func (m *missingReplaceTargetError) Error() string {
+	return fmt.Sprintf("missing replace target <%s>", generateFullName(m.fullType, m.name))
+}
+
+// ErrMissingReplaceTarget is matched by errors.Is when there is no object to replace.
+var ErrMissingReplaceTarget = errors.New("missing replace target")
+
+func (m *missingReplaceTargetError) Is(target error) bool {
+	return target == ErrMissingReplaceTarget
+}
+
+type replaceTargetTypeError struct {
+	object Object
+	rtp    reflect.Type
+}
+
+func newReplaceTargetTypeError(object Object, rtp reflect.Type) *replaceTargetTypeError {
+	return &replaceTargetTypeError{object: object, rtp: rtp}
+}
+
+func (r *replaceTargetTypeError) Error() string {
+	return fmt.Sprintf("object <%s> can not replace [%s]", generateObjectFullName(r.object), generateFullType(r.rtp))
 }
 
 type missingObjectError struct {
@@ -69,7 +103,7 @@ func newMultipleObjectError(rtp reflect.Type, nameExpr string, objects []Object)
 func (m *multipleObjectError) Error() string {
 	var foundObjectFullNames []string
 	for _, object := range m.objects {
-		foundObjectFullNames = append(foundObjectFullNames, generateFullName(object.FullType(), object.Name()))
+		foundObjectFullNames = append(foundObjectFullNames, generateObjectFullName(object))
 	}
 	return fmt.Sprintf("multiple objects found for <%s> %v", generateFullName(generateFullType(m.rtp), m.nameExpr), foundObjectFullNames)
 }
@@ -87,7 +121,7 @@ func newMultipleImplementationError(rtp reflect.Type, nameExpr string, objects [
 func (m *multipleImplementationError) Error() string {
 	var foundObjectFullNames []string
 	for _, object := range m.objects {
-		foundObjectFullNames = append(foundObjectFullNames, generateFullName(object.FullType(), object.Name()))
+		foundObjectFullNames = append(foundObjectFullNames, generateObjectFullName(object))
 	}
 	return fmt.Sprintf(`multiple implementations found for <%s> %v`, generateFullName(generateFullType(m.rtp), m.nameExpr), foundObjectFullNames)
 }
@@ -104,6 +138,124 @@ func (m *missingValueError) Error() string {
 	return fmt.Sprintf("missing value <%s>", m.value)
 }
 
+type circularPlaceholderError struct {
+	keys []string
+}
+
+func newCircularPlaceholderError(keys []string) *circularPlaceholderError {
+	return &circularPlaceholderError{keys: keys}
+}
+
+func (c *circularPlaceholderError) Error() string {
+	return fmt.Sprintf("circular placeholder detected <%s>", strings.Join(c.keys, " -> "))
+}
+
+type convertValueError struct {
+	key string
+	rtp reflect.Type
+	err error
+}
+
+func newConvertValueError(key string, rtp reflect.Type, err error) *convertValueError {
+	return &convertValueError{key: key, rtp: rtp, err: err}
+}
+
+func (c *convertValueError) Error() string {
+	return fmt.Sprintf("convert value <%s> to [%s] failed, err=%s", c.key, c.rtp, c.err)
+}
+
+func (c *convertValueError) Unwrap() error {
+	return c.err
+}
+
+type decryptValueError struct {
+	name string
+	err  error
+}
+
+func newDecryptValueError(name string, err error) *decryptValueError {
+	return &decryptValueError{name: name, err: err}
+}
+
+func (d *decryptValueError) Error() string {
+	return fmt.Sprintf("decrypt value of <%s> failed, err=%s", d.name, d.err)
+}
+
+func (d *decryptValueError) Unwrap() error {
+	return d.err
+}
+
+type loadValuesError struct {
+	provider string
+	err      error
+}
+
+func newLoadValuesError(provider string, err error) *loadValuesError {
+	return &loadValuesError{provider: provider, err: err}
+}
+
+func (l *loadValuesError) Error() string {
+	return fmt.Sprintf("load values of <%s> failed, err=%s", l.provider, l.err)
+}
+
+func (l *loadValuesError) Unwrap() error {
+	return l.err
+}
+
+type unsupportedValueTypeError struct {
+	key string
+	rtp reflect.Type
+}
+
+func newUnsupportedValueTypeError(key string, rtp reflect.Type) *unsupportedValueTypeError {
+	return &unsupportedValueTypeError{key: key, rtp: rtp}
+}
+
+func (u *unsupportedValueTypeError) Error() string {
+	return fmt.Sprintf("unsupported value type [%s] of <%s>", u.rtp, u.key)
+}
+
+type unsupportedValueKeyError struct {
+	key string
+}
+
+func newUnsupportedValueKeyError(key string) *unsupportedValueKeyError {
+	return &unsupportedValueKeyError{key: key}
+}
+
+func (u *unsupportedValueKeyError) Error() string {
+	return fmt.Sprintf("unsupported value key <%s>, the elements of lists can't be set", u.key)
+}
+
+type unknownConfigKeysError struct {
+	prefix string
+	keys   []string
+}
+
+func newUnknownConfigKeysError(prefix string, keys []string) *unknownConfigKeysError {
+	return &unknownConfigKeysError{prefix: prefix, keys: keys}
+}
+
+func (u *unknownConfigKeysError) Error() string {
+	return fmt.Sprintf("unknown config keys of <%s> %v", u.prefix, u.keys)
+}
+
+type valueValidationError struct {
+	violations []*valueViolation
+}
+
+func newValueValidationError(violations []*valueViolation) *valueValidationError {
+	return &valueValidationError{violations: violations}
+}
+
+func (v *valueValidationError) Error() string {
+	messages := make([]string, len(v.violations))
+	for i, violation := range v.violations {
+		messages[i] = violation.String()
+	}
+	return fmt.Sprintf("invalid values [%s]", strings.Join(messages, "; "))
+}
+
 type unsupportedDependencyType struct {
 	dependency Dependency
 }
@@ -189,6 +341,43 @@ func (u *unsupportedInjectFieldTypeError) Error() string {
 	return fmt.Sprintf("unsupported inject field type [%s]", u.field.Type.Kind())
 }
 
+type unsupportedResultFieldTypeError struct {
+	field reflect.StructField
+}
+
+func newUnsupportedResultFieldTypeError(field reflect.StructField) *unsupportedResultFieldTypeError {
+	return &unsupportedResultFieldTypeError{field: field}
+}
+
+func (u *unsupportedResultFieldTypeError) Error() string {
+	return fmt.Sprintf("unsupported result field type [%s]", u.field.Type)
+}
+
+type nilResultFieldError struct {
+	rtp   reflect.Type
+	field reflect.StructField
+}
+
+func newNilResultFieldError(rtp reflect.Type, field reflect.StructField) *nilResultFieldError {
+	return &nilResultFieldError{rtp: rtp, field: field}
+}
+
+func (n *nilResultFieldError) Error() string {
+	return fmt.Sprintf("result field <%s> of [%s] is nil", n.field.Name, n.rtp)
+}
+
+type moduleAlreadyGroupedError struct {
+	name string
+}
+
+func newModuleAlreadyGroupedError(name string) *moduleAlreadyGroupedError {
+	return &moduleAlreadyGroupedError{name: name}
+}
+
+func (m *moduleAlreadyGroupedError) Error() string {
+	return fmt.Sprintf("<%s> already grouped in a module", m.name)
+}
+
 type unsupportedObjectTypeError struct {
 	rtp reflect.Type
 }
@@ -9,6 +9,7 @@ type Field interface {
 	Type() reflect.Type
 	Assign(val any)
 	Append(val any)
+	Value() any
 }
 
 type structField struct {
@@ -46,3 +47,7 @@ func (f *structField) Append(val any) {
 	}
 	f.v.Set(reflect.Append(f.v, reflect.ValueOf(val)))
 }
+
+func (f *structField) Value() any {
+	return f.v.Interface()
+}
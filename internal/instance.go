@@ -76,13 +76,22 @@ func (b *constructorInstanceBuilder) Build(args []any) (any, error) {
 		}
 		index := indexes[0]
 		if len(indexes) == 1 {
-			incomes[index].Set(reflect.ValueOf(arg))
+			// optional dependencies that are missing keep the zero value of the param
+			if arg != nil {
+				incomes[index].Set(reflect.ValueOf(arg))
+			}
 			continue
 		}
 		fn(incomes[index], arg, indexes[1:])
 	}
 
-	outcomes := cv.Call(incomes)
+	var outcomes []reflect.Value
+	if ct.IsVariadic() {
+		// the last income is already the slice of variadic args
+		outcomes = cv.CallSlice(incomes)
+	} else {
+		outcomes = cv.Call(incomes)
+	}
 	if len(outcomes) == 2 && !outcomes[1].IsNil() {
 		return nil, outcomes[1].Interface().(error)
 	}
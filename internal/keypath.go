@@ -0,0 +1,109 @@
+package ioc
+
+import (
+	"strconv"
+	"strings"
+)
+
+// splitKey splits the key expression into the keys of the nested maps and the indexes of the lists,
+// the keys containing dots are quoted and the indexes are kept with their brackets.
+// Example: `servers[0].labels."app.kubernetes.io/name"` is split into ["servers", "[0]", "labels", "app.kubernetes.io/name"].
+func splitKey(expr string) []string {
+	var keys []string
+	var sb strings.Builder
+	// started is true if a key is started, even an empty quoted key
+	started, afterIndex := false, false
+	flush := func() {
+		keys = append(keys, sb.String())
+		sb.Reset()
+		started = false
+	}
+
+	for i := 0; i < len(expr); i++ {
+		switch ch := expr[i]; {
+		case ch == '"' && !started:
+			// an unclosed quote is a part of the key
+			if end := findQuoteEnd(expr, i+1); end >= 0 {
+				sb.WriteString(unquoteKey(expr[i+1 : end]))
+				started = true
+				i = end
+				continue
+			}
+			sb.WriteByte(ch)
+			started = true
+		case ch == '.':
+			if started || !afterIndex {
+				flush()
+			}
+			afterIndex = false
+		case ch == '[':
+			if end := strings.IndexByte(expr[i:], ']'); end > 0 {
+				if _, ok := parseIndexKey(expr[i : i+end+1]); ok {
+					if started {
+						flush()
+					}
+					keys = append(keys, expr[i:i+end+1])
+					afterIndex = true
+					i += end
+					continue
+				}
+			}
+			sb.WriteByte(ch)
+			started = true
+		default:
+			sb.WriteByte(ch)
+			started = true
+		}
+	}
+	if started || !afterIndex {
+		flush()
+	}
+	return keys
+}
+
+// findQuoteEnd returns the index of the unescaped quote closing the quoted key starting at start, or -1 if not closed.
+func findQuoteEnd(s string, start int) int {
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func unquoteKey(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// quoteKey quotes the key of a map if it contains the characters of key expressions or placeholders, eg. `"app.kubernetes.io/name"`.
+func quoteKey(key string) string {
+	if !strings.ContainsAny(key, `."[]:\`) {
+		return key
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(key) + `"`
+}
+
+// parseIndexKey returns the index of a key like "[0]".
+func parseIndexKey(key string) (int, bool) {
+	if len(key) < 3 || key[0] != '[' || key[len(key)-1] != ']' {
+		return 0, false
+	}
+	i, err := strconv.Atoi(key[1 : len(key)-1])
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return i, true
+}
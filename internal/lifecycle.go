@@ -4,8 +4,9 @@ type ObjectInitializing interface {
 	Init() error
 }
 
-func processObjectInitializing(instance any) error {
+func processObjectInitializing(object Object, instance any) error {
 	if oi, ok := instance.(ObjectInitializing); ok {
+		object.MarkInitCalled()
 		return oi.Init()
 	}
 	return nil
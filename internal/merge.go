@@ -0,0 +1,103 @@
+package ioc
+
+// MergeMode is the mode of looking up a key across the value providers.
+type MergeMode int
+
+const (
+	// MergeOverride returns the value of the provider with the highest priority providing the key.
+	MergeOverride MergeMode = iota
+	// MergeDeep merges the maps of the key across all the providers, the lists are merged by the ListStrategy.
+	MergeDeep
+)
+
+// ListStrategy is the strategy of merging the lists of a key in the MergeDeep mode.
+type ListStrategy int
+
+const (
+	// ListReplace replaces the list by the list of the provider with the higher priority.
+	ListReplace ListStrategy = iota
+	// ListAppend appends the list of the provider with the higher priority to the list.
+	ListAppend
+	// ListMergeByIndex merges the elements of the lists by their indexes, the maps at the same index are merged deeply.
+	ListMergeByIndex
+)
+
+func (c *valueManagerImpl) SetMergeMode(mode MergeMode, listStrategy ListStrategy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.mergeMode = mode
+	c.listStrategy = listStrategy
+}
+
+// mergeLookup returns the values of the key merged across the value maps up to the index of the highest priority.
+func (c *valueManagerImpl) mergeLookup(keys []string, index int) any {
+	var merged any
+	for i := 0; i <= index; i++ {
+		if c.valueMaps[i] == nil {
+			continue
+		}
+		value := c.getValue(i, keys)
+		if value == nil {
+			continue
+		}
+		merged = mergeValues(merged, value, c.listStrategy, c.valueSources[i].relaxed)
+	}
+	return merged
+}
+
+// mergeValues merges the value of the higher priority into the value, the maps are merged deeply into copies.
+// The keys of the higher value are matched relaxedly if relaxed, eg. "maxconns" of the environment variables and "max_conns" of a file.
+func mergeValues(value any, higher any, listStrategy ListStrategy, relaxed bool) any {
+	switch h := higher.(type) {
+	case map[string]any:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return higher
+		}
+		merged := make(map[string]any, len(m)+len(h))
+		for k, v := range m {
+			merged[k] = v
+		}
+		matcher := newKeyMatcher(m)
+		for k, v := range h {
+			mk, ok := k, false
+			if relaxed {
+				mk, ok = matcher.Key(k)
+			} else {
+				_, ok = merged[k]
+			}
+			if ok {
+				merged[mk] = mergeValues(merged[mk], v, listStrategy, relaxed)
+				continue
+			}
+			merged[k] = v
+		}
+		return merged
+	case []any:
+		l, ok := value.([]any)
+		if !ok {
+			return higher
+		}
+		switch listStrategy {
+		case ListAppend:
+			merged := make([]any, 0, len(l)+len(h))
+			return append(append(merged, l...), h...)
+		case ListMergeByIndex:
+			merged := make([]any, len(l))
+			copy(merged, l)
+			for i, v := range h {
+				if i < len(merged) {
+					merged[i] = mergeValues(merged[i], v, listStrategy, relaxed)
+					continue
+				}
+				merged = append(merged, v)
+			}
+			return merged
+		default:
+			return higher
+		}
+	default:
+		return higher
+	}
+}
@@ -0,0 +1,142 @@
+package ioc
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ModuleOptions struct {
+	Prefix        string
+	ConditionExpr string
+}
+
+type ModuleOption func(o *ModuleOptions)
+
+// Module groups objects and sub modules.
+// Private objects of a module are only visible to the objects of the module and its sub modules.
+type Module interface {
+	Name() string
+	// Path returns the names of the module and its parents joined by "/", eg. "app/billing".
+	Path() string
+	Parent() Module
+	Condition() string
+	Objects() []Object
+	// Contains reports whether the module is m or one of its parents.
+	Contains(m Module) bool
+	setParent(parent Module)
+	replaceObject(old Object, object Object)
+}
+
+type moduleImpl struct {
+	name      string
+	condition string
+	parent    Module
+	objects   []Object
+	modules   []Module
+}
+
+func newModule(name string, condition string) *moduleImpl {
+	return &moduleImpl{name: name, condition: condition}
+}
+
+func (m *moduleImpl) Name() string {
+	return m.name
+}
+
+func (m *moduleImpl) Path() string {
+	if m.parent == nil {
+		return m.name
+	}
+	return fmt.Sprintf("%s/%s", m.parent.Path(), m.name)
+}
+
+func (m *moduleImpl) Parent() Module {
+	return m.parent
+}
+
+func (m *moduleImpl) Condition() string {
+	if m.parent == nil {
+		return m.condition
+	}
+	return joinConditions(m.parent.Condition(), m.condition)
+}
+
+func (m *moduleImpl) Objects() []Object {
+	objects := append([]Object(nil), m.objects...)
+	for _, module := range m.modules {
+		objects = append(objects, module.Objects()...)
+	}
+	return objects
+}
+
+func (m *moduleImpl) Contains(module Module) bool {
+	for ; module != nil; module = module.Parent() {
+		if module == Module(m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *moduleImpl) setParent(parent Module) {
+	m.parent = parent
+}
+
+func (m *moduleImpl) addObject(object Object) {
+	object.SetModule(m)
+	m.objects = append(m.objects, object)
+}
+
+// replaceObject replaces the old object with the object, or removes the old object if the object is nil.
+func (m *moduleImpl) replaceObject(old Object, object Object) {
+	for i, o := range m.objects {
+		if o != old {
+			continue
+		}
+		if object == nil {
+			m.objects = append(m.objects[:i], m.objects[i+1:]...)
+			return
+		}
+		object.SetModule(m)
+		m.objects[i] = object
+		return
+	}
+}
+
+func (m *moduleImpl) addModule(module Module) {
+	module.setParent(m)
+	m.modules = append(m.modules, module)
+}
+
+// Registration holds the objects added by a single register call.
+type Registration struct {
+	objects []Object
+}
+
+func newRegistration(objects ...Object) *Registration {
+	return &Registration{objects: objects}
+}
+
+func (r *Registration) Objects() []Object {
+	return r.objects
+}
+
+// Override records an object replaced by Replace or removed by Unregister.
+type Override struct {
+	Old Object
+	// New is nil if the old object is unregistered.
+	New Object
+}
+
+func joinConditions(conditions ...string) string {
+	var exprs []string
+	for _, condition := range conditions {
+		if condition != "" {
+			exprs = append(exprs, condition)
+		}
+	}
+	if len(exprs) <= 1 {
+		return strings.Join(exprs, "")
+	}
+	return fmt.Sprintf("(%s)", strings.Join(exprs, ") && ("))
+}
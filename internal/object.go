@@ -24,8 +24,16 @@ type Object interface {
 	Instance() any
 	Status() ObjectStatus
 	StartInitialization()
+	AbortInitialization()
+	// InitCalled reports whether the Init method of the instance has been called.
+	InitCalled() bool
+	MarkInitCalled()
 	Build(args []any) (any, error)
 	Optional() bool
+	Private() bool
+	Module() Module
+	SetModule(module Module)
+	SetName(name string, aliases []string)
 }
 
 type objectImpl struct {
@@ -35,10 +43,13 @@ type objectImpl struct {
 	dependencies    []Dependency
 	instanceBuilder InstanceBuilder
 	optional        bool
+	private         bool
 	condition       string
+	module          Module
 	instance        any
 	inited          bool
 	initializing    bool
+	initCalled      bool
 }
 
 func newObject(
@@ -47,6 +58,7 @@ func newObject(
 	aliases []string,
 	condition string,
 	optional bool,
+	private bool,
 	dependencies []Dependency,
 	instanceBuilder InstanceBuilder,
 ) *objectImpl {
@@ -56,6 +68,7 @@ func newObject(
 		aliases:         aliases,
 		condition:       condition,
 		optional:        optional,
+		private:         private,
 		dependencies:    dependencies,
 		instanceBuilder: instanceBuilder,
 	}
@@ -73,8 +86,29 @@ func (o *objectImpl) Optional() bool {
 	return o.optional
 }
 
+func (o *objectImpl) Private() bool {
+	return o.private
+}
+
+// Condition returns the condition of the object joined with the conditions of its modules.
 func (o *objectImpl) Condition() string {
-	return o.condition
+	if o.module == nil {
+		return o.condition
+	}
+	return joinConditions(o.module.Condition(), o.condition)
+}
+
+func (o *objectImpl) Module() Module {
+	return o.module
+}
+
+func (o *objectImpl) SetModule(module Module) {
+	o.module = module
+}
+
+func (o *objectImpl) SetName(name string, aliases []string) {
+	o.name = name
+	o.aliases = aliases
 }
 
 func (o *objectImpl) Dependencies() []Dependency {
@@ -99,6 +133,18 @@ func (o *objectImpl) StartInitialization() {
 	o.initializing = true
 }
 
+func (o *objectImpl) AbortInitialization() {
+	o.initializing = false
+}
+
+func (o *objectImpl) InitCalled() bool {
+	return o.initCalled
+}
+
+func (o *objectImpl) MarkInitCalled() {
+	o.initCalled = true
+}
+
 func (o *objectImpl) Build(args []any) (any, error) {
 	instance, err := o.instanceBuilder.Build(args)
 	if err != nil {
@@ -134,7 +180,9 @@ func (b *baseObjectBuilder) parseDependencies(rtp reflect.Type, fIndex []int) ([
 				}
 			} else if valueTagExpr, ok := field.Tag.Lookup(TagValueKey); ok {
 				valueTag := ParseValueTag(valueTagExpr)
-				dependency = newValueDependency(valueTag.Value(), field.Type, valueTag.Optional())
+				dependency = newValueDependencyWithTag(valueTag, field.Type)
+			} else if configTagExpr, ok := field.Tag.Lookup(TagConfigKey); ok {
+				dependency = newConfigDependency(ParseConfigTag(configTagExpr), field.Type)
 			} else if field.Type.Kind() == reflect.Struct {
 				err := fn(field.Type, fi)
 				if err != nil {
@@ -202,6 +250,66 @@ func (b *baseObjectBuilder) parseInjectDependency(field reflect.StructField, inj
 	}
 }
 
+func (b *baseObjectBuilder) parseConstructorDependencies(constructor any) ([]Dependency, [][]int, error) {
+	ct := reflect.TypeOf(constructor)
+
+	var dependencies []Dependency
+	var injectArgIndexes [][]int
+
+	for i := 0; i < ct.NumIn(); i++ {
+		pt := ct.In(i)
+		ai := []int{i}
+		var dependency Dependency
+		switch pt.Kind() {
+		case reflect.Ptr:
+			dependency = newObjectDependency("", pt.Elem(), true)
+		case reflect.Interface:
+			dependency = newObjectDependency("", pt, true)
+		case reflect.Slice:
+			// collection params match all objects regardless of name,
+			// variadic params are received as a slice, eg. "func(handlers ...MyInterface)"
+			if pt.Elem().Kind() == reflect.Interface {
+				// eg. "func(list []MyInterface)"
+				dependency = newObjectListDependency("*", pt.Elem(), true)
+			} else if pt.Elem().Kind() == reflect.Ptr && pt.Elem().Elem().Kind() == reflect.Struct {
+				// eg. "func(list []*MyStruct)"
+				dependency = newObjectListDependency("*", pt.Elem().Elem(), true)
+			} else {
+				return nil, nil, newUnsupportedConstructorParamTypeError(constructor, pt)
+			}
+		case reflect.Map:
+			if pt.Key().Kind() != reflect.String {
+				return nil, nil, newUnsupportedConstructorParamTypeError(constructor, pt)
+			}
+			if pt.Elem().Kind() == reflect.Interface {
+				// eg. "func(m map[string]MyInterface)"
+				dependency = newObjectMapDependency("*", pt.Elem(), true)
+			} else if pt.Elem().Kind() == reflect.Ptr && pt.Elem().Elem().Kind() == reflect.Struct {
+				// eg. "func(m map[string]*MyStruct)"
+				dependency = newObjectMapDependency("*", pt.Elem().Elem(), true)
+			} else {
+				return nil, nil, newUnsupportedConstructorParamTypeError(constructor, pt)
+			}
+		case reflect.Struct:
+			deps, indexes, err := b.parseDependencies(pt, ai)
+			if err != nil {
+				return nil, nil, err
+			}
+			dependencies = append(dependencies, deps...)
+			injectArgIndexes = append(injectArgIndexes, indexes...)
+			continue
+		default:
+			return nil, nil, newUnsupportedConstructorParamTypeError(constructor, pt)
+		}
+		if dependency != nil {
+			dependencies = append(dependencies, dependency)
+			injectArgIndexes = append(injectArgIndexes, ai)
+		}
+	}
+
+	return dependencies, injectArgIndexes, nil
+}
+
 type fieldsObjectBuilder struct {
 	*baseObjectBuilder
 }
@@ -225,8 +333,9 @@ func (f *fieldsObjectBuilder) Build(rtp reflect.Type, options RegisterOptions) (
 		objRef,
 		options.Name,
 		options.Aliases,
-		options.ConditionExpr,
+		options.Condition(),
 		options.Optional,
+		options.Private,
 		dependencies,
 		newFieldInstanceBuilder(objRef.RType(), injectFieldIndexes),
 	)
@@ -259,41 +368,18 @@ func (c *constructorObjectBuilder) Build(rtp reflect.Type, options RegisterOptio
 		return nil, newConstructorNotReturnObjectError(options.Constructor, rtp)
 	}
 
-	var dependencies []Dependency
-	var injectArgIndexes [][]int
-
-	for i := 0; i < ct.NumIn(); i++ {
-		pt := ct.In(i)
-		ai := []int{i}
-		var dependency Dependency
-		switch pt.Kind() {
-		case reflect.Ptr:
-			dependency = newObjectDependency("", pt.Elem(), true)
-		case reflect.Interface:
-			dependency = newObjectDependency("", pt, true)
-		case reflect.Struct:
-			deps, indexes, err := c.parseDependencies(pt, ai)
-			if err != nil {
-				return nil, err
-			}
-			dependencies = append(dependencies, deps...)
-			injectArgIndexes = append(injectArgIndexes, indexes...)
-			continue
-		default:
-			return nil, newUnsupportedConstructorParamTypeError(options.Constructor, pt)
-		}
-		if dependency != nil {
-			dependencies = append(dependencies, dependency)
-			injectArgIndexes = append(injectArgIndexes, ai)
-		}
+	dependencies, injectArgIndexes, err := c.parseConstructorDependencies(options.Constructor)
+	if err != nil {
+		return nil, err
 	}
 
 	obj := newObject(
 		of,
 		options.Name,
 		options.Aliases,
-		options.ConditionExpr,
+		options.Condition(),
 		options.Optional,
+		options.Private,
 		dependencies,
 		newConstructorInstanceBuilder(options.Constructor, injectArgIndexes),
 	)
@@ -303,20 +389,27 @@ func (c *constructorObjectBuilder) Build(rtp reflect.Type, options RegisterOptio
 
 type ObjectBuilderFactory interface {
 	GetBuilder(options RegisterOptions) ObjectBuilder
+	GetResultBuilder() ResultObjectBuilder
 }
 
 type objectBuilderFactoryImpl struct {
 	fieldsObjectBuilder      *fieldsObjectBuilder
 	constructorObjectBuilder *constructorObjectBuilder
+	resultObjectBuilder      *resultObjectBuilder
 }
 
 func newObjectBuilderFactoryImpl() *objectBuilderFactoryImpl {
 	return &objectBuilderFactoryImpl{
 		fieldsObjectBuilder:      newFieldsObjectBuilder(),
 		constructorObjectBuilder: newConstructorObjectBuilder(),
+		resultObjectBuilder:      newResultObjectBuilder(),
 	}
 }
 
+func (f *objectBuilderFactoryImpl) GetResultBuilder() ResultObjectBuilder {
+	return f.resultObjectBuilder
+}
+
 func (f *objectBuilderFactoryImpl) GetBuilder(options RegisterOptions) ObjectBuilder {
 	if options.Constructor != nil {
 		return f.constructorObjectBuilder
@@ -325,16 +418,34 @@ func (f *objectBuilderFactoryImpl) GetBuilder(options RegisterOptions) ObjectBui
 }
 
 type ObjectManager interface {
-	AddObject(object Object) error
+	// AddObject adds the object, it fails if there is an object registered with the same type and name,
+	// unless deferDuplicate is set, then the duplicate is reported by CheckDuplicates.
+	AddObject(object Object, deferDuplicate bool) error
+	// ReplaceObject replaces the registered object of rtp with the name of the object, and returns the replaced one.
+	// If rtp is an interface, the replaced object is the implementation of rtp with the name.
+	ReplaceObject(object Object, rtp reflect.Type) (Object, error)
+	// RemoveObjects removes the registered objects with the name, and returns the removed ones.
+	// If rtp is an interface, all the implementations with the name are removed.
+	RemoveObjects(rtp reflect.Type, name string) ([]Object, error)
+	RenameObject(object Object, name string, aliases []string)
+	// CheckDuplicates fails if there are objects registered with the same type and name.
+	CheckDuplicates() error
+	// ListObjects returns the objects whose conditions are satisfied,
+	// it fails if there are objects registered with the same type and name.
 	ListObjects() ([]Object, error)
-	GetObject(rtp reflect.Type, nameExpr string) (Object, error)
-	GetObjects(rtp reflect.Type, nameExpr string) ([]Object, error)
+	// GetObject returns the object visible to the scope, the scope is nil outside any module.
+	GetObject(scope Module, rtp reflect.Type, nameExpr string) (Object, error)
+	GetObjects(scope Module, rtp reflect.Type, nameExpr string) ([]Object, error)
+	// Clone returns a copy of the manager with the registered objects not built yet.
+	Clone(conditionExecutor ConditionExecutor) ObjectManager
 }
 
 type objectManagerImpl struct {
-	mu                          sync.Mutex
-	objects                     []Object
-	registeredObjectTypeToName  map[string]map[string]bool
+	mu      sync.Mutex
+	objects []Object
+	// registeredObjectTypeToName counts the objects of the names, the duplicates may be deferred,
+	// so that the objects registered with the same name can be renamed by the prefix of their module
+	registeredObjectTypeToName  map[string]map[string]int
 	interfaceToObjectTypeToImpl map[string]map[string]bool
 	conditionExecutor           ConditionExecutor
 }
@@ -345,21 +456,146 @@ func newObjectManagerImpl(conditionExecutor ConditionExecutor) *objectManagerImp
 	}
 }
 
-func (p *objectManagerImpl) AddObject(object Object) error {
+func (p *objectManagerImpl) AddObject(object Object, deferDuplicate bool) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if p.registeredObjectTypeToName[object.FullType()][object.Name()] {
-		return newObjectDuplicateRegisterError(object.FullType(), object.Name())
+	if !deferDuplicate && p.registeredObjectTypeToName[object.FullType()][object.Name()] > 0 {
+		return newObjectDuplicateRegisterError(object)
 	}
+	p.addName(object.FullType(), object.Name())
+	p.objects = append(p.objects, object)
+	return nil
+}
+
+func (p *objectManagerImpl) addName(fullType string, name string) {
 	if p.registeredObjectTypeToName == nil {
-		p.registeredObjectTypeToName = make(map[string]map[string]bool)
+		p.registeredObjectTypeToName = make(map[string]map[string]int)
 	}
-	if _, ok := p.registeredObjectTypeToName[object.FullType()]; !ok {
-		p.registeredObjectTypeToName[object.FullType()] = make(map[string]bool)
+	if _, ok := p.registeredObjectTypeToName[fullType]; !ok {
+		p.registeredObjectTypeToName[fullType] = make(map[string]int)
+	}
+	p.registeredObjectTypeToName[fullType][name]++
+}
+
+func (p *objectManagerImpl) removeName(fullType string, name string) {
+	p.registeredObjectTypeToName[fullType][name]--
+	if p.registeredObjectTypeToName[fullType][name] <= 0 {
+		delete(p.registeredObjectTypeToName[fullType], name)
+	}
+}
+
+func (p *objectManagerImpl) ReplaceObject(object Object, rtp reflect.Type) (Object, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// the replacement must be injectable wherever the replaced object is
+	if object.RType() != rtp && (rtp.Kind() != reflect.Interface || !object.Implements(rtp)) {
+		return nil, newReplaceTargetTypeError(object, rtp)
+	}
+
+	var targets []Object
+	index := -1
+	for i, o := range p.objects {
+		if o.Name() == object.Name() && (o.RType() == rtp || (rtp.Kind() == reflect.Interface && o.Implements(rtp))) {
+			targets = append(targets, o)
+			index = i
+		}
+	}
+	if len(targets) == 0 {
+		return nil, newMissingReplaceTargetError(generateFullType(rtp), object.Name())
+	}
+	if len(targets) > 1 {
+		return nil, newMultipleImplementationError(rtp, object.Name(), targets)
+	}
+
+	old := p.objects[index]
+	p.removeName(old.FullType(), old.Name())
+	p.addName(object.FullType(), object.Name())
+	p.objects[index] = object
+	return old, nil
+}
+
+func (p *objectManagerImpl) RemoveObjects(rtp reflect.Type, name string) ([]Object, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var removed []Object
+	var objects []Object
+	for _, object := range p.objects {
+		if object.Name() == name && (object.RType() == rtp || (rtp.Kind() == reflect.Interface && object.Implements(rtp))) {
+			removed = append(removed, object)
+			p.removeName(object.FullType(), name)
+			continue
+		}
+		objects = append(objects, object)
+	}
+	if len(removed) == 0 {
+		if rtp.Kind() == reflect.Interface {
+			return nil, newMissingImplementationError(generateFullType(rtp), name)
+		}
+		return nil, newMissingObjectError(generateFullType(rtp), name)
+	}
+
+	p.objects = objects
+	return removed, nil
+}
+
+func (p *objectManagerImpl) indexOf(fullType string, name string) int {
+	if p.registeredObjectTypeToName[fullType][name] == 0 {
+		return -1
+	}
+	for i, object := range p.objects {
+		if object.FullType() == fullType && object.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *objectManagerImpl) RenameObject(object Object, name string, aliases []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.removeName(object.FullType(), object.Name())
+	p.addName(object.FullType(), name)
+	object.SetName(name, aliases)
+}
+
+func (p *objectManagerImpl) Clone(conditionExecutor ConditionExecutor) ObjectManager {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cloner := newObjectCloner()
+	clone := newObjectManagerImpl(conditionExecutor)
+	for _, object := range p.objects {
+		clone.objects = append(clone.objects, cloner.cloneObject(object))
+	}
+	cloner.fillModules()
+
+	clone.registeredObjectTypeToName = make(map[string]map[string]int, len(p.registeredObjectTypeToName))
+	for fullType, names := range p.registeredObjectTypeToName {
+		clone.registeredObjectTypeToName[fullType] = make(map[string]int, len(names))
+		for name, count := range names {
+			clone.registeredObjectTypeToName[fullType][name] = count
+		}
+	}
+	return clone
+}
+
+func (p *objectManagerImpl) CheckDuplicates() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.checkDuplicates()
+}
+
+func (p *objectManagerImpl) checkDuplicates() error {
+	for _, obj := range p.objects {
+		if p.registeredObjectTypeToName[obj.FullType()][obj.Name()] > 1 {
+			return newObjectDuplicateRegisterError(obj)
+		}
 	}
-	p.registeredObjectTypeToName[object.FullType()][object.Name()] = true
-	p.objects = append(p.objects, object)
 	return nil
 }
 
@@ -367,6 +603,12 @@ func (p *objectManagerImpl) ListObjects() ([]Object, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	// the duplicates deferred by a module never grouped are reported here
+	err := p.checkDuplicates()
+	if err != nil {
+		return nil, err
+	}
+
 	var objects []Object
 	for _, obj := range p.objects {
 		checked, err := p.checkObjectCondition(obj)
@@ -381,8 +623,8 @@ func (p *objectManagerImpl) ListObjects() ([]Object, error) {
 	return objects, nil
 }
 
-func (p *objectManagerImpl) GetObject(rtp reflect.Type, nameExpr string) (Object, error) {
-	objects, err := p.GetObjects(rtp, nameExpr)
+func (p *objectManagerImpl) GetObject(scope Module, rtp reflect.Type, nameExpr string) (Object, error) {
+	objects, err := p.GetObjects(scope, rtp, nameExpr)
 	if err != nil {
 		return nil, err
 	}
@@ -400,7 +642,7 @@ func (p *objectManagerImpl) GetObject(rtp reflect.Type, nameExpr string) (Object
 	return objects[0], nil
 }
 
-func (p *objectManagerImpl) GetObjects(rtp reflect.Type, nameExpr string) ([]Object, error) {
+func (p *objectManagerImpl) GetObjects(scope Module, rtp reflect.Type, nameExpr string) ([]Object, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -431,6 +673,10 @@ func (p *objectManagerImpl) GetObjects(rtp reflect.Type, nameExpr string) ([]Obj
 			return nil, newUnsupportedObjectTypeError(rtp)
 		}
 
+		if !p.checkObjectVisible(object, scope) {
+			continue
+		}
+
 		checked, err := p.checkObjectName(object, nameExpr)
 		if err != nil {
 			return nil, err
@@ -459,6 +705,13 @@ func (p *objectManagerImpl) checkObjectCondition(obj Object) (bool, error) {
 	return p.conditionExecutor.Execute(obj.Condition())
 }
 
+func (p *objectManagerImpl) checkObjectVisible(obj Object, scope Module) bool {
+	if !obj.Private() || obj.Module() == nil {
+		return true
+	}
+	return obj.Module().Contains(scope)
+}
+
 func (p *objectManagerImpl) checkObjectName(obj Object, nameExpr string) (bool, error) {
 	for _, name := range append([]string{obj.Name()}, obj.Aliases()...) {
 		matched, err := filepath.Match(nameExpr, name)
@@ -518,3 +771,12 @@ func generateFullType(t reflect.Type) string {
 func generateFullName(fullType, name string) string {
 	return fmt.Sprintf("%s@%s", fullType, name)
 }
+
+// generateObjectFullName returns the full name of the object prefixed by its module path.
+func generateObjectFullName(object Object) string {
+	fullName := generateFullName(object.FullType(), object.Name())
+	if object.Module() == nil {
+		return fullName
+	}
+	return fmt.Sprintf("%s:%s", object.Module().Path(), fullName)
+}
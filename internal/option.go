@@ -1,11 +1,38 @@
 package ioc
 
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
 type RegisterOptions struct {
-	Name          string
-	Aliases       []string
-	Optional      bool
+	Name     string
+	Aliases  []string
+	Optional bool
+	Private  bool
+	Replace  bool
+	// ReplaceType is the struct or interface of the replace target, the type of the object if nil.
+	ReplaceType   reflect.Type
 	Constructor   any
 	ConditionExpr string
+	Profiles      []string
+}
+
+var profileEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, `"`, `\"`)
+
+// Condition returns the condition expression joined with the profile condition.
+func (o *RegisterOptions) Condition() string {
+	if len(o.Profiles) == 0 {
+		return o.ConditionExpr
+	}
+
+	var profiles []string
+	for _, profile := range o.Profiles {
+		// the quotes and backslashes are escaped, so that any profile name is a string literal
+		profiles = append(profiles, fmt.Sprintf("'%s'", profileEscaper.Replace(profile)))
+	}
+	return joinConditions(o.ConditionExpr, fmt.Sprintf("profile(%s)", strings.Join(profiles, ", ")))
 }
 
 type RegisterOption func(o *RegisterOptions)
@@ -0,0 +1,177 @@
+package ioc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaskedValue replaces the values of the keys matching the mask patterns in the explanations and dumps.
+const MaskedValue = "******"
+
+// DefaultMaskPatterns are the patterns of the secret keys masked by default, see matchKeyPattern.
+var DefaultMaskPatterns = []string{
+	"**.*password*",
+	"**.*secret*",
+	"**.*token*",
+	"**.*credential*",
+	"**.*apikey*",
+	"**.*privatekey*",
+}
+
+// LocatedValueProvider is implemented by the value providers knowing where their values are defined, eg. a file.
+type LocatedValueProvider interface {
+	ValueProvider
+	// Locate returns the file and the line of the keys of the last provided values, the line is 0 if unknown.
+	Locate(keys []string) (file string, line int)
+}
+
+// ValueOrigin is a provider providing a key, File and Line are set if the provider is a LocatedValueProvider.
+type ValueOrigin struct {
+	Source string
+	File   string
+	Line   int
+	// Value is the value of the provider before the placeholders are resolved
+	Value any
+}
+
+// String returns the source of the origin with the line if known, eg. "file config.yaml:3".
+func (o ValueOrigin) String() string {
+	if o.Line > 0 {
+		return fmt.Sprintf("%s:%d", o.Source, o.Line)
+	}
+	return o.Source
+}
+
+// ValueInfo is a resolved value with its origins ordered by priority,
+// the first origin supplies the value and overrides the others, or is merged over them in the MergeDeep mode.
+type ValueInfo struct {
+	Key     string
+	Value   any
+	Origins []ValueOrigin
+}
+
+// String returns the value with its origins, eg. "app.port = 8080 (file config-prod.yaml:2), overrides 80 (file config.yaml:5)".
+func (i *ValueInfo) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s = %v", i.Key, i.Value)
+	for j, origin := range i.Origins {
+		if j == 0 {
+			fmt.Fprintf(&sb, " (%s)", origin)
+			continue
+		}
+		if j == 1 {
+			sb.WriteString(", overrides ")
+		} else {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%v (%s)", origin.Value, origin)
+	}
+	return sb.String()
+}
+
+func (c *valueManagerImpl) ExplainValue(key string) (*ValueInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok, err := c.getProperty(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, newMissingValueError(key)
+	}
+	return c.explain(key), nil
+}
+
+func (c *valueManagerImpl) DumpValues() ([]*ValueInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	infos := make([]*ValueInfo, 0, len(keys))
+	for _, key := range keys {
+		infos = append(infos, c.explain(key))
+	}
+	return infos, nil
+}
+
+func (c *valueManagerImpl) AddMaskPatterns(patterns ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, pattern := range patterns {
+		c.maskPatterns = append(c.maskPatterns, splitKeyPattern(pattern))
+	}
+}
+
+// explain returns the resolved value of the key with the values of all the providers providing it,
+// the encrypted values are masked instead of decrypted.
+func (c *valueManagerImpl) explain(key string) *ValueInfo {
+	value, _ := c.lookup(key)
+	resolver := newPlaceholderResolver(c.lookup)
+	resolver.maskEncrypted = true
+	if resolved, err := resolver.Resolve(value); err == nil {
+		value = resolved
+	}
+
+	keys := splitKey(key)
+	info := &ValueInfo{Key: key, Value: c.maskValue(keys, value)}
+	for i := len(c.valueMaps) - 1; i >= 0; i-- {
+		vm := c.valueMaps[i]
+		if vm == nil {
+			continue
+		}
+		v := c.getValue(i, keys)
+		if v == nil {
+			continue
+		}
+
+		source := c.valueSources[i]
+		origin := ValueOrigin{Source: source.name, Value: c.maskValue(keys, v)}
+		if lp, ok := source.provider.(LocatedValueProvider); ok {
+			// the provider locates the keys as it provides them, eg. "max_conns" for "maxConns"
+			if path, ok := vm.KeyPath(keys, source.relaxed); ok {
+				origin.File, origin.Line = lp.Locate(path)
+			}
+		}
+		info.Origins = append(info.Origins, origin)
+	}
+	return info
+}
+
+// maskValue returns MaskedValue if the keys match a mask pattern, the nested values of maps and lists are masked by their keys.
+func (c *valueManagerImpl) maskValue(keys []string, value any) any {
+	for _, pattern := range c.maskPatterns {
+		if matchKeyPattern(pattern, keys) {
+			return MaskedValue
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		masked := make(map[string]any, len(v))
+		for k, e := range v {
+			masked[k] = c.maskValue(append(keys[:len(keys):len(keys)], k), e)
+		}
+		return masked
+	case []any:
+		masked := make([]any, len(v))
+		for i, e := range v {
+			masked[i] = c.maskValue(keys, e)
+		}
+		return masked
+	default:
+		return value
+	}
+}
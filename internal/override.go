@@ -0,0 +1,144 @@
+package ioc
+
+import (
+	"strings"
+	"sync"
+)
+
+// overrideValueProvider provides the values set at runtime by SetValue and WithValues,
+// it is layered over all the providers.
+type overrideValueProvider struct {
+	values valueMap
+}
+
+func newOverrideValueProvider() *overrideValueProvider {
+	return &overrideValueProvider{values: make(valueMap)}
+}
+
+func (p *overrideValueProvider) Provide() (map[string]any, error) {
+	return p.values, nil
+}
+
+func (p *overrideValueProvider) String() string {
+	return "runtime"
+}
+
+// clone returns a provider of a copy of the values, so the values set on either are not seen by the other.
+func (p *overrideValueProvider) clone() *overrideValueProvider {
+	return &overrideValueProvider{values: copyValue(map[string]any(p.values)).(map[string]any)}
+}
+
+// copyValue returns a deep copy of the nested maps and lists of the value.
+func copyValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(v))
+		for key, e := range v {
+			copied[key] = copyValue(e)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(v))
+		for i, e := range v {
+			copied[i] = copyValue(e)
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
+func (c *valueManagerImpl) SetValue(key string, value any) error {
+	keys, err := overrideKeys(key)
+	if err != nil {
+		return err
+	}
+
+	return c.updateOverrides(func(overrides valueMap) {
+		setOverride(overrides, keys, value)
+	})
+}
+
+func (c *valueManagerImpl) WithValues(values map[string]any) (restore func(), err error) {
+	keyToKeys := make(map[string][]string, len(values))
+	for key := range values {
+		keys, err := overrideKeys(key)
+		if err != nil {
+			return nil, err
+		}
+		keyToKeys[key] = keys
+	}
+
+	previousValues := make(map[string]any, len(values))
+	err = c.updateOverrides(func(overrides valueMap) {
+		for key, keys := range keyToKeys {
+			previousValues[key] = overrides.GetValue(keys)
+			setOverride(overrides, keys, values[key])
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			// the values are loaded already, so restoring never fails
+			_ = c.updateOverrides(func(overrides valueMap) {
+				for key, keys := range keyToKeys {
+					setOverride(overrides, keys, previousValues[key])
+				}
+			})
+		})
+	}, nil
+}
+
+// updateOverrides updates the values set at runtime and notifies the listeners of the changed values.
+func (c *valueManagerImpl) updateOverrides(update func(overrides valueMap)) error {
+	c.mu.Lock()
+
+	err := c.load()
+	if err != nil {
+		c.mu.Unlock()
+		return err
+	}
+
+	update(c.overrides.values)
+	oldValues := c.values
+	// the values set at runtime may activate other profiles, eg. SetValue(ProfilesValueKey, "prod")
+	if profiles := c.resolveProfiles(); strings.Join(profiles, ",") != strings.Join(c.activeProfiles, ",") {
+		c.loaded = false
+		err = c.load()
+		if err != nil {
+			c.mu.Unlock()
+			return err
+		}
+	}
+	c.values = c.snapshot()
+	changes := diffValues(oldValues, c.values)
+	listeners := append([]*valueListener(nil), c.listeners...)
+	c.mu.Unlock()
+
+	notifyValueChanges(listeners, changes)
+	return nil
+}
+
+// overrideKeys returns the keys of the key expression, the elements of lists can't be set.
+func overrideKeys(key string) ([]string, error) {
+	keys := splitKey(key)
+	for _, k := range keys {
+		if _, ok := parseIndexKey(k); ok {
+			return nil, newUnsupportedValueKeyError(key)
+		}
+	}
+	return keys, nil
+}
+
+// setOverride sets the value of the keys, the value is deleted if nil.
+func setOverride(overrides valueMap, keys []string, value any) {
+	if value == nil {
+		overrides.DeleteValue(keys)
+		return
+	}
+	overrides.SetValue(keys, value)
+}
@@ -0,0 +1,189 @@
+package ioc
+
+import (
+	"github.com/spf13/cast"
+	"strings"
+)
+
+const (
+	placeholderPrefix = "${"
+	placeholderSuffix = "}"
+	// placeholderEscape escapes a placeholder to keep it verbatim, eg. `\${key}` is resolved to `${key}`.
+	placeholderEscape = `\`
+)
+
+// placeholderResolver resolves the placeholders like `${key}` and `${key:default}` in the values,
+// and decrypts the encrypted values like `ENC(ciphertext)` by the registered decryptors.
+type placeholderResolver struct {
+	lookup func(expr string) (any, bool)
+	// resolving are the keys being resolved, used to detect the circular placeholders
+	resolving []string
+	// maskEncrypted resolves the encrypted values to MaskedValue instead of decrypting them
+	maskEncrypted bool
+}
+
+func newPlaceholderResolver(lookup func(expr string) (any, bool)) *placeholderResolver {
+	return &placeholderResolver{lookup: lookup}
+}
+
+// Resolve returns the value with the placeholders resolved, the maps and slices are resolved recursively into copies.
+func (r *placeholderResolver) Resolve(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return r.resolveString(v)
+	case map[string]any:
+		resolved := make(map[string]any, len(v))
+		for key, e := range v {
+			re, err := r.Resolve(e)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = re
+		}
+		return resolved, nil
+	case []any:
+		resolved := make([]any, len(v))
+		for i, e := range v {
+			re, err := r.Resolve(e)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = re
+		}
+		return resolved, nil
+	default:
+		return value, nil
+	}
+}
+
+func (r *placeholderResolver) resolveString(s string) (any, error) {
+	if decryptor, ciphertext, ok := getDecryptor(s); ok {
+		if r.maskEncrypted {
+			return MaskedValue, nil
+		}
+		plaintext, err := decryptor.Decrypt(ciphertext)
+		if err != nil {
+			return nil, newDecryptValueError(s[:strings.IndexByte(s, '(')], err)
+		}
+		return plaintext, nil
+	}
+	if !strings.Contains(s, placeholderPrefix) {
+		return s, nil
+	}
+
+	// a value of a single placeholder keeps the type of the referred value, eg. a list or a number
+	if strings.HasPrefix(s, placeholderPrefix) {
+		if end := findPlaceholderEnd(s, len(placeholderPrefix)); end == len(s)-len(placeholderSuffix) {
+			return r.resolvePlaceholder(s[len(placeholderPrefix):end])
+		}
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], placeholderEscape+placeholderPrefix) {
+			sb.WriteString(placeholderPrefix)
+			i += len(placeholderEscape) + len(placeholderPrefix)
+			continue
+		}
+		if !strings.HasPrefix(s[i:], placeholderPrefix) {
+			sb.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		start := i + len(placeholderPrefix)
+		end := findPlaceholderEnd(s, start)
+		if end < 0 {
+			// an unclosed placeholder is kept verbatim
+			sb.WriteString(s[i:])
+			break
+		}
+		value, err := r.resolvePlaceholder(s[start:end])
+		if err != nil {
+			return nil, err
+		}
+		str, err := toString(value)
+		if err != nil {
+			return nil, err
+		}
+		sb.WriteString(str)
+		i = end + len(placeholderSuffix)
+	}
+
+	return sb.String(), nil
+}
+
+func (r *placeholderResolver) resolvePlaceholder(placeholder string) (any, error) {
+	key, defaultValue, hasDefault := splitPlaceholder(placeholder)
+	if containsString(r.resolving, key) {
+		return nil, newCircularPlaceholderError(append(r.resolving, key))
+	}
+
+	value, ok := r.lookup(key)
+	if !ok {
+		if !hasDefault {
+			return nil, newMissingValueError(key)
+		}
+		// the default value may contain placeholders as well, eg. `${db.port:${default.port}}`
+		return r.resolveString(defaultValue)
+	}
+
+	r.resolving = append(r.resolving, key)
+	defer func() {
+		r.resolving = r.resolving[:len(r.resolving)-1]
+	}()
+	return r.Resolve(value)
+}
+
+// findPlaceholderEnd returns the index of the suffix closing the placeholder starting at start, or -1 if not closed.
+func findPlaceholderEnd(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		if strings.HasPrefix(s[i:], placeholderPrefix) {
+			depth++
+			i += len(placeholderPrefix) - 1
+		} else if strings.HasPrefix(s[i:], placeholderSuffix) {
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// splitPlaceholder splits the placeholder into the key and the default value by the first ":" outside the quoted keys.
+func splitPlaceholder(placeholder string) (string, string, bool) {
+	// the colons of the quoted keys are not separators, eg. `${labels."a:b":default}`
+	quoted := false
+	for i := 0; i < len(placeholder); i++ {
+		switch placeholder[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				return strings.TrimSpace(placeholder[:i]), placeholder[i+1:], true
+			}
+		}
+	}
+	return strings.TrimSpace(placeholder), "", false
+}
+
+func toString(value any) (string, error) {
+	if slice, ok := value.([]any); ok {
+		parts := make([]string, len(slice))
+		for i, e := range slice {
+			part, err := toString(e)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, ","), nil
+	}
+	return cast.ToStringE(value)
+}
@@ -0,0 +1,96 @@
+package ioc
+
+import (
+	"errors"
+	"io/fs"
+	"time"
+)
+
+// ProviderOptions are the options of a value provider added to the manager.
+type ProviderOptions struct {
+	// Name is the name of the provider in the explanations and statuses, the default name is the String of the provider.
+	Name string
+	// Optional skips the provider if its source is missing, ie. it fails with an error matching fs.ErrNotExist,
+	// eg. a missing local override file.
+	Optional bool
+	// Priority orders the providers, the providers with higher priorities override the values of the others,
+	// the providers of the same priority are ordered by their insertion.
+	Priority int
+}
+
+type ProviderOption func(o *ProviderOptions)
+
+// ProviderStatus is the status of a value provider at its last load.
+type ProviderStatus struct {
+	Name     string
+	Priority int
+	Optional bool
+	// Err is the error of the last load, the values of the provider are skipped if it is optional and its source is missing
+	Err error
+	// LoadedAt is the start time of the last load, it is zero if the provider is never loaded
+	LoadedAt time.Time
+	// Duration is the duration of the last load, including the profile-specific variants of the provider
+	Duration time.Duration
+}
+
+// valueProviderEntry is a value provider added to the manager with its options.
+type valueProviderEntry struct {
+	provider ValueProvider
+	options  ProviderOptions
+}
+
+func (e *valueProviderEntry) Name() string {
+	if e.options.Name != "" {
+		return e.options.Name
+	}
+	return providerName(e.provider)
+}
+
+// valueSource is the provider of a value map with the name of the provider.
+type valueSource struct {
+	name     string
+	provider ValueProvider
+	// relaxed is true if the keys of the provider are matched relaxedly, see RelaxedValueProvider
+	relaxed bool
+}
+
+func newValueSource(name string, provider ValueProvider) *valueSource {
+	source := &valueSource{name: name, provider: provider}
+	if rp, ok := provider.(RelaxedValueProvider); ok {
+		source.relaxed = rp.RelaxedKeys()
+	}
+	return source
+}
+
+// provide loads the values of the provider of the entry, or of its variant of the profile if the profile is not empty,
+// the duration and the error skipping the values are recorded into the status of the entry.
+// A missing variant, ie. an error matching fs.ErrNotExist, has no values to skip, so it is not an error.
+func (c *valueManagerImpl) provide(entry *valueProviderEntry, provider ValueProvider, profile string, status *ProviderStatus) (valueMap, error) {
+	start := time.Now()
+	if status.LoadedAt.IsZero() {
+		status.LoadedAt = start
+	}
+	vm, err := provider.Provide()
+	status.Duration += time.Since(start)
+	if err != nil {
+		if profile != "" && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		status.Err = err
+		// only a missing source is skipped, eg. a malformed optional file still fails
+		if entry.options.Optional && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, newLoadValuesError(providerName(provider), err)
+	}
+	return vm, nil
+}
+
+func (c *valueManagerImpl) ProviderStatuses() []ProviderStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the errors are recorded into the statuses
+	_ = c.load()
+	return append([]ProviderStatus(nil), c.statuses...)
+}
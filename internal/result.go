@@ -0,0 +1,141 @@
+package ioc
+
+import "reflect"
+
+// Out marks a struct returned by a constructor as a result struct.
+// Each field of a result struct is registered as a separate object,
+// the name and aliases of the object are read from the `provide` tag of the field.
+type Out struct{}
+
+var outType = reflect.TypeOf(Out{})
+
+func isResultType(rtp reflect.Type) bool {
+	if rtp.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < rtp.NumField(); i++ {
+		field := rtp.Field(i)
+		if field.Anonymous && field.Type == outType {
+			return true
+		}
+	}
+	return false
+}
+
+type ResultObjectBuilder interface {
+	Build(options RegisterOptions) ([]Object, error)
+}
+
+type resultObjectBuilder struct {
+	*baseObjectBuilder
+}
+
+func newResultObjectBuilder() *resultObjectBuilder {
+	return &resultObjectBuilder{}
+}
+
+func (r *resultObjectBuilder) Build(options RegisterOptions) ([]Object, error) {
+	ct := reflect.TypeOf(options.Constructor)
+	if ct.Kind() != reflect.Func {
+		return nil, newUnsupportedConstructorError(options.Constructor)
+	}
+	if ct.NumOut() > 2 || ct.NumOut() == 0 || (ct.NumOut() == 2 && ct.Out(1).Name() != "error") {
+		return nil, newUnsupportedConstructorError(options.Constructor)
+	}
+	rt := ct.Out(0)
+	if !isResultType(rt) {
+		return nil, newUnsupportedConstructorError(options.Constructor)
+	}
+
+	dependencies, injectArgIndexes, err := r.parseConstructorDependencies(options.Constructor)
+	if err != nil {
+		return nil, err
+	}
+
+	// all objects of the result share one constructor call
+	result := newConstructorResult(newConstructorInstanceBuilder(options.Constructor, injectArgIndexes))
+
+	var objects []Object
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.Anonymous && field.Type == outType {
+			continue
+		}
+		if field.Type.Kind() != reflect.Ptr || field.Type.Elem().Kind() != reflect.Struct {
+			// eg. "MyInterface" or "int"
+			return nil, newUnsupportedResultFieldTypeError(field)
+		}
+
+		objRef, err := parseObjectRef(field.Type.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		provideTag := ParseProvideTag(field.Tag.Get(TagProvideKey))
+		obj := newObject(
+			objRef,
+			provideTag.Value(),
+			provideTag.Aliases(),
+			options.Condition(),
+			options.Optional || provideTag.Optional(),
+			options.Private,
+			dependencies,
+			newResultFieldInstanceBuilder(result, i),
+		)
+		objects = append(objects, obj)
+	}
+
+	return objects, nil
+}
+
+type constructorResult struct {
+	instanceBuilder InstanceBuilder
+	built           bool
+	value           reflect.Value
+}
+
+func newConstructorResult(instanceBuilder InstanceBuilder) *constructorResult {
+	return &constructorResult{instanceBuilder: instanceBuilder}
+}
+
+// Get calls the constructor on the first request and returns the cached result afterward.
+func (r *constructorResult) Get(args []any) (reflect.Value, error) {
+	if r.built {
+		return r.value, nil
+	}
+
+	instance, err := r.instanceBuilder.Build(args)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	// copy to an addressable value, so that unexported fields can be read
+	value := reflect.New(reflect.TypeOf(instance)).Elem()
+	value.Set(reflect.ValueOf(instance))
+	r.value = value
+	r.built = true
+	return r.value, nil
+}
+
+type resultFieldInstanceBuilder struct {
+	result     *constructorResult
+	fieldIndex int
+}
+
+func newResultFieldInstanceBuilder(result *constructorResult, fieldIndex int) *resultFieldInstanceBuilder {
+	return &resultFieldInstanceBuilder{result: result, fieldIndex: fieldIndex}
+}
+
+func (b *resultFieldInstanceBuilder) Build(args []any) (any, error) {
+	rv, err := b.result.Get(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// a field left nil by the constructor is an error, rather than an object of a typed nil
+	field := rv.Field(b.fieldIndex)
+	if field.IsNil() {
+		return nil, newNilResultFieldError(rv.Type(), rv.Type().Field(b.fieldIndex))
+	}
+	return newStructField(field).Value(), nil
+}
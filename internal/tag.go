@@ -3,8 +3,11 @@ package ioc
 import "strings"
 
 const (
-	TagInjectKey = "inject"
-	TagValueKey  = "value"
+	TagInjectKey   = "inject"
+	TagValueKey    = "value"
+	TagProvideKey  = "provide"
+	TagPropertyKey = "property"
+	TagConfigKey   = "config"
 )
 
 type Tag struct {
@@ -39,6 +42,16 @@ func (t Tag) HasOption(key string) bool {
 	return false
 }
 
+// Option returns the value of a `key=value` option.
+func (t Tag) Option(key string) (string, bool) {
+	for _, opt := range t.options {
+		if k, v, ok := strings.Cut(opt, "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
 type InjectTag struct {
 	Tag
 }
@@ -62,3 +75,46 @@ func ParseValueTag(tag string) ValueTag {
 func (t ValueTag) Optional() bool {
 	return t.HasOption("optional")
 }
+
+// Default returns the value of the `default=value` option used if the value is missing.
+func (t ValueTag) Default() (string, bool) {
+	return t.Option("default")
+}
+
+type PropertyTag struct {
+	Tag
+}
+
+func ParsePropertyTag(tag string) PropertyTag {
+	return PropertyTag{ParseTag(tag)}
+}
+
+// Required returns true if the property must be provided, the missing properties are skipped by default.
+func (t PropertyTag) Required() bool {
+	return t.HasOption("required")
+}
+
+// Default returns the value of the `default=value` option used if the property is missing.
+func (t PropertyTag) Default() (string, bool) {
+	return t.Option("default")
+}
+
+type ProvideTag struct {
+	Tag
+}
+
+func ParseProvideTag(tag string) ProvideTag {
+	return ProvideTag{ParseTag(tag)}
+}
+
+func (t ProvideTag) Aliases() []string {
+	aliases, ok := t.Option("alias")
+	if !ok || aliases == "" {
+		return nil
+	}
+	return strings.Split(aliases, ",")
+}
+
+func (t ProvideTag) Optional() bool {
+	return t.HasOption("optional")
+}
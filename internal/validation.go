@@ -0,0 +1,297 @@
+package ioc
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Validator is implemented by the values validating themselves after they are bound, eg. a config struct.
+type Validator interface {
+	Validate() error
+}
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// valueViolation is a value violating a constraint of its tag or its Validate method.
+type valueViolation struct {
+	key     string
+	source  string
+	message string
+}
+
+func (v *valueViolation) String() string {
+	if v.source == "" {
+		return fmt.Sprintf("<%s> %s", v.key, v.message)
+	}
+	return fmt.Sprintf("<%s> %s (%s)", v.key, v.message, v.source)
+}
+
+// ValidateValue validates the value of the key by the constraints of the tag, eg. `min=1` or `oneof=a|b`,
+// the fields of structs are validated by the constraints of their tags and their Validate methods.
+// All the violations are returned in one error.
+// The value is validated without the lock, so the Validate methods and the converters may read the values.
+func (c *valueManagerImpl) ValidateValue(key string, value any, tag Tag) error {
+	var violations []*valueViolation
+	c.validate(key, reflect.ValueOf(value), tag, &violations)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, violation := range violations {
+		violation.source = c.sourceOf(violation.key)
+	}
+	return newValueValidationError(violations)
+}
+
+func (c *valueManagerImpl) validate(key string, v reflect.Value, tag Tag, violations *[]*valueViolation) {
+	if !v.IsValid() {
+		return
+	}
+
+	addViolation := func(message string) {
+		*violations = append(*violations, &valueViolation{key: key, message: message})
+	}
+	for _, message := range c.checkConstraints(v, tag) {
+		addViolation(message)
+	}
+
+	ev := v
+	for ev.Kind() == reflect.Pointer || ev.Kind() == reflect.Interface {
+		if ev.IsNil() {
+			return
+		}
+		ev = ev.Elem()
+	}
+
+	if _, ok := getConverter(ev.Type()); !ok {
+		switch ev.Kind() {
+		case reflect.Struct:
+			if isDynamicValueType(ev.Type()) {
+				break
+			}
+			c.validateFields(key, ev, violations)
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < ev.Len(); i++ {
+				c.validate(indexKey(key, i), ev.Index(i), Tag{}, violations)
+			}
+		case reflect.Map:
+			iter := ev.MapRange()
+			for iter.Next() {
+				c.validate(joinKeys(key, quoteKey(fmt.Sprint(iter.Key().Interface()))), iter.Value(), Tag{}, violations)
+			}
+		}
+	}
+
+	if validator, ok := toValidator(v); ok {
+		if err := validator.Validate(); err != nil {
+			addViolation(err.Error())
+		}
+	}
+}
+
+func (c *valueManagerImpl) validateFields(key string, v reflect.Value, violations *[]*valueViolation) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+		if fv.CanAddr() {
+			fv = newStructField(fv).v
+		}
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			c.validateFields(key, fv, violations)
+			continue
+		}
+
+		names, tag, ok := configFieldNames(f)
+		if !ok {
+			continue
+		}
+		c.validate(joinKeys(key, names[0]), fv, tag.Tag, violations)
+	}
+}
+
+// toValidator returns the Validator of the value or the pointer to the value.
+func toValidator(v reflect.Value) (Validator, bool) {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil, false
+	}
+	if v.Type().Implements(validatorType) && v.CanInterface() {
+		return v.Interface().(Validator), true
+	}
+	if v.Kind() != reflect.Pointer && reflect.PointerTo(v.Type()).Implements(validatorType) {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return ptr.Interface().(Validator), true
+	}
+	return nil, false
+}
+
+// checkConstraints returns the messages of the constraints of the tag violated by the value.
+func (c *valueManagerImpl) checkConstraints(v reflect.Value, tag Tag) []string {
+	var messages []string
+	if tag.HasOption("nonempty") && isEmptyValue(v) {
+		messages = append(messages, "must not be empty")
+	}
+
+	ev := v
+	for ev.Kind() == reflect.Pointer || ev.Kind() == reflect.Interface {
+		if ev.IsNil() {
+			return messages
+		}
+		ev = ev.Elem()
+	}
+
+	if min, ok := tag.Option("min"); ok {
+		if less, err := c.compareLimit(ev, min); err != nil {
+			messages = append(messages, err.Error())
+		} else if less < 0 {
+			messages = append(messages, fmt.Sprintf("must be at least %s", min))
+		}
+	}
+	if max, ok := tag.Option("max"); ok {
+		if greater, err := c.compareLimit(ev, max); err != nil {
+			messages = append(messages, err.Error())
+		} else if greater > 0 {
+			messages = append(messages, fmt.Sprintf("must be at most %s", max))
+		}
+	}
+	if oneOf, ok := tag.Option("oneof"); ok {
+		s := fmt.Sprint(ev.Interface())
+		if !containsString(strings.Split(oneOf, "|"), s) {
+			messages = append(messages, fmt.Sprintf("must be one of [%s]", strings.ReplaceAll(oneOf, "|", ", ")))
+		}
+	}
+	if pattern, ok := tag.Option("regex"); ok {
+		reg, err := regexp.Compile(pattern)
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("invalid regex %q", pattern))
+		} else if !reg.MatchString(fmt.Sprint(ev.Interface())) {
+			messages = append(messages, fmt.Sprintf("must match %s", pattern))
+		}
+	}
+
+	return messages
+}
+
+// compareLimit compares the value to the limit, the numbers are compared by their values,
+// the strings, lists and maps are compared by their lengths.
+// The limit is a plain string, so converting it never reads the values.
+func (c *valueManagerImpl) compareLimit(v reflect.Value, limit string) (int, error) {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		if _, ok := getConverter(v.Type()); !ok {
+			n, err := c.convertType("", limit, reflect.TypeOf(0))
+			if err != nil {
+				return 0, fmt.Errorf("invalid limit %q", limit)
+			}
+			return compareFloats(float64(v.Len()), float64(n.(int))), nil
+		}
+	}
+
+	// the limit is converted to the type of the value, eg. "1s" for a time.Duration
+	converted, err := c.convertType("", limit, v.Type())
+	if err != nil {
+		return 0, fmt.Errorf("invalid limit %q", limit)
+	}
+	lv := reflect.ValueOf(converted)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareFloats(float64(v.Int()), float64(lv.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareFloats(float64(v.Uint()), float64(lv.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return compareFloats(v.Float(), lv.Float()), nil
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Compare(converted.(time.Time)), nil
+	}
+	return 0, fmt.Errorf("limit not supported by [%s]", v.Type())
+}
+
+func compareFloats(a float64, b float64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+// ValidateValues resolves and validates the values injected into the registered objects without building them,
+// all the missing and invalid values are returned in one error.
+func (c *ContainerImpl) ValidateValues() error {
+	objects, err := c.ListObjects()
+	if err != nil {
+		return err
+	}
+
+	var violations []*valueViolation
+	reported := make(map[string]bool)
+	for _, object := range objects {
+		for _, dependency := range object.Dependencies() {
+			var err error
+			switch dep := dependency.(type) {
+			case *valueDependency:
+				if isDynamicValueType(dep.RType()) {
+					rtp := dep.RType()
+					if rtp.Kind() == reflect.Pointer {
+						rtp = rtp.Elem()
+					}
+					handle := reflect.New(rtp).Interface().(DynamicValue)
+					_, err = newValueBinding(c.valueManager, dep).Load(handle.ValueType())
+				} else {
+					_, err = c.getDependencyValue(dep)
+				}
+			case *configDependency:
+				_, err = c.getDependencyConfig(dep)
+			default:
+				continue
+			}
+			if err == nil {
+				continue
+			}
+
+			var depViolations []*valueViolation
+			switch e := err.(type) {
+			case *valueValidationError:
+				depViolations = e.violations
+			case *missingValueError:
+				depViolations = []*valueViolation{{key: e.value, message: "is missing"}}
+			default:
+				depViolations = []*valueViolation{{key: dependency.NameExpr(), message: err.Error()}}
+			}
+			for _, violation := range depViolations {
+				// the values injected into several objects are reported once
+				if !reported[violation.String()] {
+					reported[violation.String()] = true
+					violations = append(violations, violation)
+				}
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return newValueValidationError(violations)
+	}
+	return nil
+}
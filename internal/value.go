@@ -2,8 +2,11 @@ package ioc
 
 import (
 	"errors"
+	"fmt"
 	"github.com/spf13/cast"
+	"os"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -11,22 +14,133 @@ import (
 type valueMap map[string]any
 
 func (m valueMap) GetValue(keys []string) any {
+	return m.getValue(keys, false)
+}
+
+// getValue returns the value of the keys, the keys are matched relaxedly if relaxed, see Key.
+func (m valueMap) getValue(keys []string, relaxed bool) any {
 	if len(keys) == 0 {
 		return nil
 	}
 
-	key := keys[0]
-	value := m[key]
-	if len(keys) == 1 {
+	k, ok := m.Key(keys[0], relaxed)
+	if !ok {
+		// a key like "max_conns" may be provided as nested keys "max.conns", eg. by environment variables
+		if parts := strings.FieldsFunc(keys[0], isKeySeparator); relaxed && len(parts) > 1 {
+			return m.getValue(append(parts, keys[1:]...), relaxed)
+		}
+		return nil
+	}
+
+	return getNestedValue(m[k], keys[1:], relaxed)
+}
+
+// getNestedValue returns the value of the keys nested in the value, the lists are indexed by keys like "[0]".
+func getNestedValue(value any, keys []string, relaxed bool) any {
+	if len(keys) == 0 {
 		return value
 	}
 
-	next, ok := value.(map[string]any)
-	if !ok {
+	switch v := value.(type) {
+	case map[string]any:
+		return valueMap(v).getValue(keys, relaxed)
+	case []any:
+		i, ok := parseIndexKey(keys[0])
+		if !ok || i >= len(v) {
+			return nil
+		}
+		return getNestedValue(v[i], keys[1:], relaxed)
+	default:
 		return nil
 	}
+}
+
+// KeyPath returns the keys of the maps matching the keys like getValue, eg. ["db", "max", "conns"] for "db.max_conns".
+func (m valueMap) KeyPath(keys []string, relaxed bool) ([]string, bool) {
+	if len(keys) == 0 {
+		return nil, false
+	}
+
+	k, ok := m.Key(keys[0], relaxed)
+	if !ok {
+		if parts := strings.FieldsFunc(keys[0], isKeySeparator); relaxed && len(parts) > 1 {
+			return m.KeyPath(append(parts, keys[1:]...), relaxed)
+		}
+		return nil, false
+	}
+	path := []string{k}
+	value := m[k]
+	for i, key := range keys[1:] {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := valueMap(v).KeyPath(keys[i+1:], relaxed)
+			if !ok {
+				return nil, false
+			}
+			return append(path, next...), true
+		case []any:
+			j, ok := parseIndexKey(key)
+			if !ok || j >= len(v) {
+				return nil, false
+			}
+			path = append(path, key)
+			value = v[j]
+		default:
+			return nil, false
+		}
+	}
+	return path, true
+}
+
+// Key returns the key of the map matching the key, if relaxed and the key is not found,
+// the key matches its CanonicalKey, as the keys of the relaxed maps are canonical, eg. "maxConns" matches "maxconns".
+func (m valueMap) Key(key string, relaxed bool) (string, bool) {
+	if _, ok := m[key]; ok {
+		return key, true
+	}
+	if !relaxed {
+		return "", false
+	}
+
+	ck := CanonicalKey(key)
+	if _, ok := m[ck]; ok {
+		return ck, true
+	}
+	return "", false
+}
+
+// keyMatcher matches the keys of a map exactly, or relaxedly ignoring case, "-" and "_",
+// eg. the field name "MaxConns" matches "max_conns", the first of the sorted keys wins if several keys match relaxedly.
+type keyMatcher struct {
+	m         map[string]any
+	canonical map[string]string
+}
 
-	return valueMap(next).GetValue(keys[1:])
+func newKeyMatcher(m map[string]any) *keyMatcher {
+	return &keyMatcher{m: m}
+}
+
+func (k *keyMatcher) Key(key string) (string, bool) {
+	if _, ok := k.m[key]; ok {
+		return key, true
+	}
+
+	if k.canonical == nil {
+		keys := make([]string, 0, len(k.m))
+		for mk := range k.m {
+			keys = append(keys, mk)
+		}
+		sort.Strings(keys)
+		k.canonical = make(map[string]string, len(keys))
+		for _, mk := range keys {
+			ck := CanonicalKey(mk)
+			if _, ok := k.canonical[ck]; !ok {
+				k.canonical[ck] = mk
+			}
+		}
+	}
+	mk, ok := k.canonical[CanonicalKey(key)]
+	return mk, ok
 }
 
 func (m valueMap) SetValue(keys []string, value any) {
@@ -40,85 +154,516 @@ func (m valueMap) SetValue(keys []string, value any) {
 		return
 	}
 
-	next, ok := m[key].(valueMap)
+	next, ok := m[key].(map[string]any)
 	if !ok {
-		next = make(valueMap)
+		next = make(map[string]any)
 		m[key] = next
 	}
 
-	next.SetValue(keys[1:], value)
+	valueMap(next).SetValue(keys[1:], value)
+}
+
+// DeleteValue deletes the value of the keys, the maps left empty are deleted as well.
+func (m valueMap) DeleteValue(keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	key := keys[0]
+	if len(keys) == 1 {
+		delete(m, key)
+		return
+	}
+
+	next, ok := m[key].(map[string]any)
+	if !ok {
+		return
+	}
+	valueMap(next).DeleteValue(keys[1:])
+	if len(next) == 0 {
+		delete(m, key)
+	}
 }
 
+func isKeySeparator(r rune) bool {
+	return r == '-' || r == '_'
+}
+
+// CanonicalKey returns the key lowercased without "-" and "_", eg. "maxconns" for "max_conns" and "MaxConns".
+func CanonicalKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(key))
+}
+
+const (
+	// ProfilesEnvKey is the environment variable activating the profiles, eg. `IOC_PROFILES_ACTIVE=dev,local`.
+	ProfilesEnvKey = "IOC_PROFILES_ACTIVE"
+	// ProfilesValueKey is the value key activating the profiles.
+	ProfilesValueKey = "ioc.profiles.active"
+)
+
 type ValueProvider interface {
 	Provide() (map[string]any, error)
 }
 
+// RelaxedValueProvider is implemented by the value providers whose keys are normalized by CanonicalKey,
+// eg. environment variables, the keys are matched relaxedly in their values, see valueMap.Key.
+type RelaxedValueProvider interface {
+	ValueProvider
+	RelaxedKeys() bool
+}
+
+// ProfileValueProvider is implemented by the value providers having profile-specific variants.
+type ProfileValueProvider interface {
+	ValueProvider
+	// ProfileProvider returns the provider of the profile, eg. the provider of "config-prod.yaml" for "config.yaml",
+	// the variant is skipped if its provider returns an error matching fs.ErrNotExist.
+	ProfileProvider(profile string) ValueProvider
+}
+
 type ValueManager interface {
 	// AddValueProvider adds a new value provider to the manager.
-	// The last added provider will be used first when retrieving values.
-	AddValueProvider(provider ValueProvider)
+	// The provider with the highest priority will be used first when retrieving values,
+	// the last added provider is used first among the providers of the same priority.
+	AddValueProvider(provider ValueProvider, options ProviderOptions)
+	// Clone returns a copy of the manager with the same providers, the values are loaded again.
+	Clone() ValueManager
+	// SetProfiles activates the profiles, overriding the profiles activated by values.
+	SetProfiles(profiles []string)
+	ActiveProfiles() ([]string, error)
 	GetProperty(expr string) (any, bool, error)
 	GetValueWithType(expr string, rtp reflect.Type) (any, bool, error)
+	// Refresh reloads the values of the providers and notifies the listeners of the changed values,
+	// the values are kept if any provider fails.
+	Refresh() error
+	// OnValueChange adds a listener of the changed values whose keys match the pattern, see matchKeyPattern.
+	OnValueChange(pattern string, fn ValueChangeListener) (cancel func())
+	// OnValueChanges is like OnValueChange, but fn is called once per refresh with all the matching changes.
+	OnValueChanges(pattern string, fn ValueChangesListener) (cancel func())
+	// Watch refreshes the values when any WatchableValueProvider signals a change until stop is called.
+	Watch() (stop func())
+	// BindConfig binds the values of the prefix onto the struct type by the names of its fields.
+	BindConfig(prefix string, rtp reflect.Type, options BindOptions) (any, error)
+	// SetMergeMode sets the mode of looking up the keys across the providers, the default mode is MergeOverride.
+	SetMergeMode(mode MergeMode, listStrategy ListStrategy)
+	// ValidateValue validates the value of the key by the constraints of the tag and the Validate methods.
+	ValidateValue(key string, value any, tag Tag) error
+	// ExplainValue returns the resolved value of the key with the values of all the providers providing it.
+	ExplainValue(key string) (*ValueInfo, error)
+	// DumpValues returns the resolved values of all the keys with their origins sorted by key.
+	DumpValues() ([]*ValueInfo, error)
+	// AddMaskPatterns masks the values of the keys matching the patterns in ExplainValue and DumpValues.
+	AddMaskPatterns(patterns ...string)
+	// SetValue sets the value of the key over the values of all the providers and notifies the listeners,
+	// the value set before is removed if the value is nil.
+	SetValue(key string, value any) error
+	// WithValues sets the values of the keys like SetValue until restore is called.
+	WithValues(values map[string]any) (restore func(), err error)
+	// ConvertValue resolves the placeholders of the value of the key and converts it to the type, eg. the default value of a tag.
+	ConvertValue(key string, value any, rtp reflect.Type) (any, error)
+	// ProviderStatuses returns the statuses of the providers at their last load ordered by priority.
+	ProviderStatuses() []ProviderStatus
 }
 
 type valueManagerImpl struct {
-	mu             sync.Mutex
-	loaded         bool
-	valueProviders []ValueProvider
+	mu     sync.Mutex
+	loaded bool
+	// valueProviders are ordered by their priorities
+	valueProviders []*valueProviderEntry
 	valueMaps      []valueMap
+	// valueSources are the providers of valueMaps
+	valueSources   []*valueSource
+	statuses       []ProviderStatus
+	profiles       []string
+	activeProfiles []string
+	listeners      []*valueListener
+	mergeMode      MergeMode
+	listStrategy   ListStrategy
+	maskPatterns   [][]string
+	overrides      *overrideValueProvider
+	// values are the flattened values of the last load, compared on refresh as the providers may share their maps
+	values map[string]any
 }
 
 func newValueManagerImpl() *valueManagerImpl {
-	return &valueManagerImpl{}
+	c := &valueManagerImpl{overrides: newOverrideValueProvider()}
+	for _, pattern := range DefaultMaskPatterns {
+		c.maskPatterns = append(c.maskPatterns, splitKeyPattern(pattern))
+	}
+	return c
+}
+
+func (c *valueManagerImpl) AddValueProvider(provider ValueProvider, options ProviderOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.valueProviders = append(c.valueProviders, &valueProviderEntry{provider: provider, options: options})
+	sort.SliceStable(c.valueProviders, func(i, j int) bool {
+		return c.valueProviders[i].options.Priority < c.valueProviders[j].options.Priority
+	})
+	// reload the values with the new provider on the next lookup
+	c.loaded = false
+	c.valueMaps = nil
+}
+
+func (c *valueManagerImpl) Clone() ValueManager {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clone := newValueManagerImpl()
+	clone.valueProviders = append(clone.valueProviders, c.valueProviders...)
+	clone.profiles = c.profiles
+	clone.mergeMode = c.mergeMode
+	clone.listStrategy = c.listStrategy
+	clone.maskPatterns = append(clone.maskPatterns[:0:0], c.maskPatterns...)
+	clone.overrides = c.overrides.clone()
+	return clone
+}
+
+func (c *valueManagerImpl) SetProfiles(profiles []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.profiles = profiles
+	// reload the values of the profiles on the next lookup
+	c.loaded = false
+	c.valueMaps = nil
 }
 
-func (c *valueManagerImpl) AddValueProvider(provider ValueProvider) {
+func (c *valueManagerImpl) ActiveProfiles() ([]string, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.valueProviders = append(c.valueProviders, provider)
+	err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.activeProfiles, nil
 }
 
 func (c *valueManagerImpl) GetProperty(expr string) (any, bool, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if !c.loaded {
-		for _, provider := range c.valueProviders {
-			if provider == nil {
-				continue
+	return c.getProperty(expr)
+}
+
+func (c *valueManagerImpl) getProperty(expr string) (any, bool, error) {
+	err := c.load()
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, ok := c.lookup(expr)
+	if !ok {
+		return nil, false, nil
+	}
+
+	// the placeholders are resolved on lookup, so they may refer to the values of any provider
+	value, err = c.resolvePlaceholders(value)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (c *valueManagerImpl) resolvePlaceholders(value any) (any, error) {
+	return newPlaceholderResolver(c.lookup).Resolve(value)
+}
+
+func (c *valueManagerImpl) Refresh() error {
+	c.mu.Lock()
+
+	var oldValues map[string]any
+	if c.loaded {
+		oldValues = c.values
+	}
+	loaded, valueMaps, valueSources, activeProfiles, values := c.loaded, c.valueMaps, c.valueSources, c.activeProfiles, c.values
+
+	c.loaded = false
+	err := c.load()
+	if err != nil {
+		// keep the last loaded values
+		c.loaded, c.valueMaps, c.valueSources, c.activeProfiles, c.values = loaded, valueMaps, valueSources, activeProfiles, values
+		c.mu.Unlock()
+		return err
+	}
+
+	var changes []ValueChange
+	if oldValues != nil {
+		changes = diffValues(oldValues, c.values)
+	}
+	listeners := append([]*valueListener(nil), c.listeners...)
+	c.mu.Unlock()
+
+	// the listeners are notified without the lock, so they can get the values
+	notifyValueChanges(listeners, changes)
+	return nil
+}
+
+func (c *valueManagerImpl) OnValueChange(pattern string, fn ValueChangeListener) (cancel func()) {
+	return c.addListener(&valueListener{pattern: splitKeyPattern(pattern), fn: fn})
+}
+
+func (c *valueManagerImpl) OnValueChanges(pattern string, fn ValueChangesListener) (cancel func()) {
+	return c.addListener(&valueListener{pattern: splitKeyPattern(pattern), batchFn: fn})
+}
+
+func (c *valueManagerImpl) addListener(listener *valueListener) (cancel func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.listeners = append(c.listeners, listener)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		for i, l := range c.listeners {
+			if l == listener {
+				c.listeners = append(c.listeners[:i:i], c.listeners[i+1:]...)
+				break
 			}
-			vm, err := provider.Provide()
-			if err != nil {
-				return nil, false, err
+		}
+	}
+}
+
+func (c *valueManagerImpl) Watch() (stop func()) {
+	c.mu.Lock()
+	var watchables []WatchableValueProvider
+	for _, entry := range c.valueProviders {
+		if wp, ok := entry.provider.(WatchableValueProvider); ok {
+			watchables = append(watchables, wp)
+		}
+	}
+	c.mu.Unlock()
+
+	// the watchers are started without the lock, as they may request their sources first
+	var stops []func()
+	for _, wp := range watchables {
+		stops = append(stops, wp.Watch(func() {
+			// the failed refresh keeps the last loaded values until the next change
+			_ = c.Refresh()
+		}))
+	}
+
+	return func() {
+		for _, s := range stops {
+			s()
+		}
+	}
+}
+
+// snapshot returns the flattened values of all the providers with the placeholders resolved.
+func (c *valueManagerImpl) snapshot() map[string]any {
+	values := make(map[string]any)
+	if c.mergeMode == MergeDeep {
+		var merged any
+		for i, vm := range c.valueMaps {
+			merged = mergeValues(merged, map[string]any(vm), c.listStrategy, c.valueSources[i].relaxed)
+		}
+		flattenValues("", merged, values)
+	} else {
+		for _, vm := range c.valueMaps {
+			flattenValues("", map[string]any(vm), values)
+		}
+	}
+	for key, value := range values {
+		// the unresolvable placeholders are compared verbatim
+		if resolved, err := c.resolvePlaceholders(value); err == nil {
+			values[key] = resolved
+		}
+	}
+	return values
+}
+
+func (c *valueManagerImpl) load() error {
+	if c.loaded {
+		return nil
+	}
+
+	baseMaps := make([]valueMap, len(c.valueProviders))
+	c.valueMaps = nil
+	c.valueSources = nil
+	c.statuses = make([]ProviderStatus, len(c.valueProviders))
+	var loadErr error
+	for i, entry := range c.valueProviders {
+		c.statuses[i] = ProviderStatus{Name: entry.Name(), Priority: entry.options.Priority, Optional: entry.options.Optional}
+		if entry.provider == nil {
+			continue
+		}
+		// the other providers are loaded anyway to record their statuses
+		vm, err := c.provide(entry, entry.provider, "", &c.statuses[i])
+		if err != nil {
+			if loadErr == nil {
+				loadErr = err
 			}
-			if vm == nil {
+			continue
+		}
+		if vm == nil {
+			continue
+		}
+		baseMaps[i] = vm
+		c.valueMaps = append(c.valueMaps, vm)
+		c.valueSources = append(c.valueSources, newValueSource(entry.Name(), entry.provider))
+	}
+	if loadErr != nil {
+		return loadErr
+	}
+
+	// the profiles may be activated by the values of the providers or the values set at runtime
+	c.appendOverrides()
+	c.activeProfiles = c.resolveProfiles()
+	if len(c.activeProfiles) > 0 {
+		var valueMaps []valueMap
+		var valueSources []*valueSource
+		for i, entry := range c.valueProviders {
+			if baseMaps[i] != nil {
+				valueMaps = append(valueMaps, baseMaps[i])
+				valueSources = append(valueSources, newValueSource(entry.Name(), entry.provider))
+			}
+			// the variants of a skipped optional provider are skipped as well
+			if c.statuses[i].Err != nil {
+				continue
+			}
+			pp, ok := entry.provider.(ProfileValueProvider)
+			if !ok {
 				continue
 			}
-			c.valueMaps = append(c.valueMaps, vm)
+			// the values of the profiles are layered over the values of the provider
+			for _, profile := range c.activeProfiles {
+				profileProvider := pp.ProfileProvider(profile)
+				vm, err := c.provide(entry, profileProvider, profile, &c.statuses[i])
+				if err != nil {
+					return err
+				}
+				if vm == nil {
+					continue
+				}
+				name := providerName(profileProvider)
+				if entry.options.Name != "" {
+					name = fmt.Sprintf("%s (profile %s)", entry.options.Name, profile)
+				}
+				valueMaps = append(valueMaps, vm)
+				valueSources = append(valueSources, newValueSource(name, profileProvider))
+			}
 		}
+		c.valueMaps = valueMaps
+		c.valueSources = valueSources
+		c.appendOverrides()
+	}
 
-		c.loaded = true
+	c.values = c.snapshot()
+	c.loaded = true
+	return nil
+}
+
+// appendOverrides layers the values set at runtime over the values of all the providers.
+func (c *valueManagerImpl) appendOverrides() {
+	c.valueMaps = append(c.valueMaps, c.overrides.values)
+	c.valueSources = append(c.valueSources, newValueSource(providerName(c.overrides), c.overrides))
+}
+
+// resolveProfiles returns the profiles set by SetProfiles,
+// or the profiles of the environment variable ProfilesEnvKey, or the profiles of the value ProfilesValueKey.
+func (c *valueManagerImpl) resolveProfiles() []string {
+	if len(c.profiles) > 0 {
+		return c.profiles
 	}
 
-	keys := strings.Split(expr, ".")
-	for i := len(c.valueMaps) - 1; i >= 0; i-- {
-		vm := c.valueMaps[i]
-		if vm == nil {
+	var value any
+	if env := os.Getenv(ProfilesEnvKey); env != "" {
+		value = env
+	} else if v, ok := c.lookup(ProfilesValueKey); ok {
+		value = v
+	}
+
+	slice, _ := toSlice(value)
+	var profiles []string
+	for _, p := range slice {
+		if profile := strings.TrimSpace(cast.ToString(p)); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+func (c *valueManagerImpl) lookup(expr string) (any, bool) {
+	i := c.lookupIndex(expr)
+	if i < 0 {
+		return nil, false
+	}
+
+	keys := splitKey(expr)
+	if c.mergeMode == MergeDeep {
+		return c.mergeLookup(keys, i), true
+	}
+
+	value := c.getValue(i, keys)
+	// the maps of the runtime values are merged over the values of the providers,
+	// so SetValue("db.host", ...) keeps the other values of "db"
+	if _, ok := value.(map[string]any); ok && c.valueSources[i].provider == ValueProvider(c.overrides) {
+		if j := c.lookupKeysIndexBelow(keys, i); j >= 0 {
+			return mergeValues(c.getValue(j, keys), value, ListReplace, false), true
+		}
+	}
+	return value, true
+}
+
+// lookupIndex returns the index of the value map providing the key, or -1 if the key is missing.
+func (c *valueManagerImpl) lookupIndex(expr string) int {
+	return c.lookupKeysIndex(splitKey(expr))
+}
+
+func (c *valueManagerImpl) lookupKeysIndex(keys []string) int {
+	return c.lookupKeysIndexBelow(keys, len(c.valueMaps))
+}
+
+// lookupKeysIndexBelow returns the index of the value map providing the keys below the index n, or -1 if missing.
+func (c *valueManagerImpl) lookupKeysIndexBelow(keys []string, n int) int {
+	for i := n - 1; i >= 0; i-- {
+		if c.valueMaps[i] == nil {
 			continue
 		}
 
-		value := vm.GetValue(keys)
+		value := c.getValue(i, keys)
 		if value != nil {
-			return value, true, nil
+			return i
 		}
 	}
 
-	return nil, false, nil
+	return -1
+}
+
+// getValue returns the value of the keys in the value map of the index,
+// the keys are matched relaxedly in the values of a RelaxedValueProvider.
+func (c *valueManagerImpl) getValue(i int, keys []string) any {
+	return c.valueMaps[i].getValue(keys, c.valueSources[i].relaxed)
+}
+
+// sourceOf returns the name of the provider of the key, or of its nearest parent key, eg. "file config.yaml".
+func (c *valueManagerImpl) sourceOf(key string) string {
+	keys := splitKey(key)
+	for j := len(keys); j > 0; j-- {
+		if i := c.lookupKeysIndex(keys[:j]); i >= 0 {
+			return c.valueSources[i].name
+		}
+	}
+	return ""
+}
+
+// providerName returns the String of the provider if implemented, or the type of the provider.
+func providerName(provider ValueProvider) string {
+	if stringer, ok := provider.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprintf("%T", provider)
 }
 
 func (c *valueManagerImpl) GetValueWithType(expr string, rtp reflect.Type) (any, bool, error) {
-	property, exist, err := c.GetProperty(expr)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	property, exist, err := c.getProperty(expr)
 	if err != nil {
 		return nil, false, err
 	}
@@ -126,7 +671,7 @@ func (c *valueManagerImpl) GetValueWithType(expr string, rtp reflect.Type) (any,
 		return nil, false, nil
 	}
 
-	v, err := c.convertType(property, rtp)
+	v, err := c.convertType(expr, property, rtp)
 	if err != nil {
 		return nil, false, err
 	}
@@ -134,13 +679,39 @@ func (c *valueManagerImpl) GetValueWithType(expr string, rtp reflect.Type) (any,
 	return v, true, nil
 }
 
-func (c *valueManagerImpl) convertType(property any, t reflect.Type) (any, error) {
+func (c *valueManagerImpl) ConvertValue(key string, value any, rtp reflect.Type) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err = c.resolvePlaceholders(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.convertType(key, value, rtp)
+}
+
+// convertType converts the property of the key to the type.
+func (c *valueManagerImpl) convertType(key string, property any, t reflect.Type) (any, error) {
 	var isPtr bool
 	if t.Kind() == reflect.Pointer {
 		t = t.Elem()
 		isPtr = true
 	}
 
+	if converter, ok := getConverter(t); ok {
+		val, err := converter(property)
+		if err != nil {
+			return nil, newConvertValueError(key, t, err)
+		}
+		return toTypedValue(val, t, isPtr), nil
+	}
+
 	if t.Kind() == reflect.Struct {
 		pm, ok := property.(map[string]any)
 		if !ok {
@@ -148,12 +719,13 @@ func (c *valueManagerImpl) convertType(property any, t reflect.Type) (any, error
 		}
 
 		entity := reflect.New(t)
+		matcher := newKeyMatcher(pm)
 		for i := 0; i < t.NumField(); i++ {
 			f := t.Field(i)
 			field := newStructField(entity.Elem().Field(i))
 
 			if f.Anonymous {
-				ok, err := c.assignProperty(field, pm)
+				ok, err := c.assignProperty(key, field, pm)
 				if err != nil {
 					return nil, err
 				}
@@ -162,15 +734,30 @@ func (c *valueManagerImpl) convertType(property any, t reflect.Type) (any, error
 				}
 				continue
 			}
-			p, ok := f.Tag.Lookup("property")
+			propertyTagExpr, ok := f.Tag.Lookup(TagPropertyKey)
 			if !ok {
 				continue
 			}
-			v, ok := pm[p]
-			if !ok {
-				continue
+			propertyTag := ParsePropertyTag(propertyTagExpr)
+			propertyKey := joinKeys(key, propertyTag.Value())
+			var v any
+			if k, ok := matcher.Key(propertyTag.Value()); ok {
+				v = pm[k]
+			}
+			if v == nil {
+				if defaultValue, ok := propertyTag.Default(); ok {
+					var err error
+					v, err = c.resolvePlaceholders(defaultValue)
+					if err != nil {
+						return nil, err
+					}
+				} else if propertyTag.Required() {
+					return nil, newMissingValueError(propertyKey)
+				} else {
+					continue
+				}
 			}
-			ok, err := c.assignProperty(field, v)
+			ok, err := c.assignProperty(propertyKey, field, v)
 			if err != nil {
 				return nil, err
 			}
@@ -187,30 +774,91 @@ func (c *valueManagerImpl) convertType(property any, t reflect.Type) (any, error
 			return nil, errors.New("pointer to slice is not supported")
 		}
 
-		slice, ok := property.([]any)
+		slice, ok := toSlice(property)
 		if !ok {
 			return nil, nil
 		}
 
 		sliceVal := reflect.MakeSlice(t, 0, len(slice))
-		for _, a := range slice {
-			convertedType, err := c.convertType(a, t.Elem())
+		for i, a := range slice {
+			convertedType, err := c.convertType(indexKey(key, i), a, t.Elem())
 			if err != nil {
 				return nil, err
 			}
 
+			if convertedType == nil {
+				sliceVal = reflect.Append(sliceVal, reflect.Zero(t.Elem()))
+				continue
+			}
 			sliceVal = reflect.Append(sliceVal, reflect.ValueOf(convertedType))
 		}
 
 		return sliceVal.Interface(), nil
 	}
 
-	val, err := c.convertBasicType(property, t, isPtr)
+	if t.Kind() == reflect.Map {
+		pm, ok := property.(map[string]any)
+		if !ok {
+			return nil, nil
+		}
+
+		mapVal := reflect.MakeMapWithSize(t, len(pm))
+		for k, v := range pm {
+			// the keys are converted like the values, eg. "1" to an int key
+			convertedKey, err := c.convertType(key, k, t.Key())
+			if err != nil {
+				return nil, err
+			}
+			convertedValue, err := c.convertType(joinKeys(key, quoteKey(k)), v, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			if convertedValue == nil {
+				mapVal.SetMapIndex(reflect.ValueOf(convertedKey), reflect.Zero(t.Elem()))
+				continue
+			}
+			mapVal.SetMapIndex(reflect.ValueOf(convertedKey), reflect.ValueOf(convertedValue))
+		}
+
+		if isPtr {
+			ptr := reflect.New(t)
+			ptr.Elem().Set(mapVal)
+			return ptr.Interface(), nil
+		}
+		return mapVal.Interface(), nil
+	}
+
+	if t.Kind() == reflect.Interface {
+		if property != nil && !reflect.TypeOf(property).Implements(t) {
+			return nil, newUnsupportedValueTypeError(key, t)
+		}
+		return property, nil
+	}
+
+	val, err := c.convertBasicType(property, t, false)
 	if err != nil {
-		return nil, err
+		return nil, newConvertValueError(key, t, err)
 	}
+	if val == nil {
+		return nil, newUnsupportedValueTypeError(key, t)
+	}
+
+	// the basic types are converted to the named types, eg. `type Level int`
+	return toTypedValue(val, t, isPtr), nil
+}
 
-	return val, nil
+// toTypedValue converts the value to the type, or to the pointer to the type.
+func toTypedValue(val any, t reflect.Type, isPtr bool) any {
+	v := reflect.ValueOf(val)
+	if v.Type() != t {
+		v = v.Convert(t)
+	}
+	if !isPtr {
+		return v.Interface()
+	}
+	ptr := reflect.New(t)
+	ptr.Elem().Set(v)
+	return ptr.Interface()
 }
 
 func (c *valueManagerImpl) convertBasicType(property any, t reflect.Type, isPtr bool) (any, error) {
@@ -346,17 +994,17 @@ func (c *valueManagerImpl) convertBasicType(property any, t reflect.Type, isPtr
 	}
 }
 
-func (c *valueManagerImpl) assignProperty(field Field, property any) (bool, error) {
+func (c *valueManagerImpl) assignProperty(key string, field Field, property any) (bool, error) {
 	t := field.Type()
 
 	if t.Kind() == reflect.Slice {
-		slice, ok := property.([]any)
+		slice, ok := toSlice(property)
 		if !ok {
 			return false, nil
 		}
 
-		for _, a := range slice {
-			convertedType, err := c.convertType(a, t.Elem())
+		for i, a := range slice {
+			convertedType, err := c.convertType(indexKey(key, i), a, t.Elem())
 			if err != nil {
 				return false, err
 			}
@@ -364,7 +1012,7 @@ func (c *valueManagerImpl) assignProperty(field Field, property any) (bool, erro
 			field.Append(convertedType)
 		}
 	} else {
-		convertedType, err := c.convertType(property, field.Type())
+		convertedType, err := c.convertType(key, property, field.Type())
 		if err != nil {
 			return false, err
 		}
@@ -376,3 +1024,36 @@ func (c *valueManagerImpl) assignProperty(field Field, property any) (bool, erro
 
 	return true, nil
 }
+
+// joinKeys joins the key of a property to the key of its parent, eg. "db" and "host" to "db.host".
+func joinKeys(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// indexKey returns the key of an element of a list, eg. "hosts[0]".
+func indexKey(key string, index int) string {
+	return fmt.Sprintf("%s[%d]", key, index)
+}
+
+// toSlice returns the list property, a string property is split by "," into a list.
+func toSlice(property any) ([]any, bool) {
+	switch p := property.(type) {
+	case []any:
+		return p, true
+	case string:
+		if p == "" {
+			return nil, true
+		}
+		parts := strings.Split(p, ",")
+		slice := make([]any, len(parts))
+		for i, part := range parts {
+			slice[i] = strings.TrimSpace(part)
+		}
+		return slice, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,136 @@
+package ioc
+
+import (
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// WatchableValueProvider is implemented by the value providers able to signal the changes of their values.
+type WatchableValueProvider interface {
+	ValueProvider
+	// Watch calls notify when the values may have changed until stop is called.
+	Watch(notify func()) (stop func())
+}
+
+// ValueChange is a changed value, Old is nil for an added key and New is nil for a removed key.
+type ValueChange struct {
+	Key string
+	Old any
+	New any
+}
+
+type ValueChangeListener func(change ValueChange)
+
+// ValueChangesListener is called with the changes of a refresh at once.
+type ValueChangesListener func(changes []ValueChange)
+
+// valueListener calls either fn for each matching change or batchFn for all of them.
+type valueListener struct {
+	pattern []string
+	fn      ValueChangeListener
+	batchFn ValueChangesListener
+}
+
+// matchKeyPattern matches the key against the pattern split by ".",
+// "*" matches a single key segment and "**" matches any number of key segments,
+// the other wildcards of path.Match match within a segment.
+// Example: "app.feature.*" matches "app.feature.login", "app.**" matches "app.db.host", "**.*password" matches "db.root_password".
+func matchKeyPattern(pattern []string, keys []string) bool {
+	if len(pattern) == 0 {
+		return len(keys) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(keys); i++ {
+			if matchKeyPattern(pattern[1:], keys[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(keys) == 0 {
+		return false
+	}
+	if !matchKeySegment(pattern[0], keys[0]) {
+		return false
+	}
+	return matchKeyPattern(pattern[1:], keys[1:])
+}
+
+func matchKeySegment(pattern string, key string) bool {
+	if pattern == "*" || CanonicalKey(pattern) == CanonicalKey(key) {
+		return true
+	}
+	if !strings.ContainsAny(pattern, "*?[") {
+		return false
+	}
+	matched, _ := path.Match(CanonicalKey(pattern), CanonicalKey(key))
+	return matched
+}
+
+// notifyValueChanges calls the listeners whose patterns match the keys of the changes,
+// the batch listeners are called after the others with all their matching changes.
+func notifyValueChanges(listeners []*valueListener, changes []ValueChange) {
+	batches := make(map[*valueListener][]ValueChange)
+	for _, change := range changes {
+		keys := splitKeyPattern(change.Key)
+		for _, listener := range listeners {
+			if !matchKeyPattern(listener.pattern, keys) {
+				continue
+			}
+			if listener.batchFn != nil {
+				batches[listener] = append(batches[listener], change)
+				continue
+			}
+			listener.fn(change)
+		}
+	}
+	for _, listener := range listeners {
+		if batch := batches[listener]; len(batch) > 0 {
+			listener.batchFn(batch)
+		}
+	}
+}
+
+// flattenValues flattens the nested maps into the dotted keys of their leaf values, lists are leaf values.
+func flattenValues(prefix string, value any, values map[string]any) {
+	m, ok := value.(map[string]any)
+	if !ok || len(m) == 0 {
+		if prefix != "" {
+			values[prefix] = value
+		}
+		return
+	}
+
+	for k, v := range m {
+		flattenValues(joinKeys(prefix, quoteKey(k)), v, values)
+	}
+}
+
+// diffValues returns the changes between the flattened values sorted by key.
+func diffValues(oldValues map[string]any, newValues map[string]any) []ValueChange {
+	var changes []ValueChange
+	for key, oldValue := range oldValues {
+		newValue, ok := newValues[key]
+		if !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, ValueChange{Key: key, Old: oldValue, New: newValue})
+		}
+	}
+	for key, newValue := range newValues {
+		if _, ok := oldValues[key]; !ok {
+			changes = append(changes, ValueChange{Key: key, New: newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+func splitKeyPattern(pattern string) []string {
+	return splitKey(pattern)
+}
@@ -2,19 +2,97 @@ package ioc
 
 import (
 	"errors"
+	"fmt"
 	ioc "github.com/sakuradon99/ioc/internal"
 	"reflect"
 )
 
 var iocContainer ioc.Container = ioc.NewContainerImpl()
 
+// Register registers the struct type T as an object.
+// The returned registration can be grouped by Module.
 func Register[T any](opts ...RegisterOption) any {
-	err := iocContainer.Register(getRefType[T](), opts...)
+	registration, err := iocContainer.Register(getRefType[T](), opts...)
 	if err != nil {
 		panic(err)
 	}
 
-	return nil
+	return registration
+}
+
+// Provide registers the objects created by the constructor.
+// If the constructor returns a pointer to a struct, it is the same as `Register[T](Constructor(constructor))`.
+// If the constructor returns a result struct which embeds `Out`, each field of the result struct
+// is registered as a separate object, the fields must be pointers to structs.
+// Example:
+//
+//	type ConnResult struct {
+//		ioc.Out
+//		Conn    *Conn          `provide:"primary;alias=db,main"`
+//		Checker *HealthChecker `provide:"primary"`
+//	}
+//
+// The constructor is called only once for all the objects of the result struct.
+func Provide(constructor any, opts ...RegisterOption) any {
+	if constructor == nil {
+		panic(errors.New("constructor cannot be nil"))
+	}
+
+	registration, err := iocContainer.Provide(constructor, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return registration
+}
+
+// Unregister removes the registered object of struct type T with the name.
+// If T is an interface, all the implementations of T with the name are removed.
+// It fails if there is no such object, the removed objects can be listed by ListOverrides.
+func Unregister[T any](name string) error {
+	rtp := getRefType[T]()
+	if rtp.Kind() != reflect.Struct && rtp.Kind() != reflect.Interface {
+		return errors.New("ref is not a struct or interface")
+	}
+
+	return iocContainer.Unregister(rtp, name)
+}
+
+// Module groups registrations and sub modules.
+// The items can be the results of Register, Provide and Module, or module options like Prefix and ModuleConditional.
+// Example:
+//
+//	var _ = ioc.Module("billing",
+//		ioc.Prefix("billing."),
+//		ioc.Register[BillingService](ioc.Name("service")),
+//		ioc.Register[BillingClient](ioc.Private()),
+//	)
+//
+// Modules can be nested, the prefixes and conditions of the parent modules are applied as well.
+// The duplicate names of the items registered after Prefix are reported when the prefixes are applied.
+func Module(name string, items ...any) any {
+	var registrations []*ioc.Registration
+	var modules []ioc.Module
+	var opts []ioc.ModuleOption
+	for _, item := range items {
+		switch it := item.(type) {
+		case *ioc.Registration:
+			registrations = append(registrations, it)
+		case ioc.Module:
+			modules = append(modules, it)
+		case ioc.ModuleOption:
+			opts = append(opts, it)
+		default:
+			panic(fmt.Errorf("unsupported module item type %T", item))
+		}
+	}
+
+	module, err := iocContainer.Module(name, registrations, modules, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return module
 }
 
 func GetObject[T any](name string) (*T, error) {
@@ -118,15 +196,212 @@ func GetInterfaceMap[T any](name string) (map[string]T, error) {
 	return ret, nil
 }
 
-func AddValueProvider(provider ValueProvider) error {
+// ObjectInfo describes a registered object.
+type ObjectInfo struct {
+	Type    string
+	RType   reflect.Type
+	Name    string
+	Aliases []string
+	// Module is the path of the module of the object, eg. "app/billing", empty if not in a module.
+	Module  string
+	Private bool
+	// Built reports whether the instance of the object has been created.
+	Built bool
+	// InitCalled reports whether the Init method of the instance has been called.
+	InitCalled bool
+}
+
+// ListObjects returns the registered objects whose conditions are satisfied.
+func ListObjects() ([]ObjectInfo, error) {
+	objects, err := iocContainer.ListObjects()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]ObjectInfo, len(objects))
+	for i, object := range objects {
+		ret[i] = newObjectInfo(object)
+	}
+	return ret, nil
+}
+
+func newObjectInfo(object ioc.Object) ObjectInfo {
+	info := ObjectInfo{
+		Type:       object.FullType(),
+		RType:      object.RType(),
+		Name:       object.Name(),
+		Aliases:    object.Aliases(),
+		Private:    object.Private(),
+		Built:      object.Status() == ioc.ObjectStatusInitialized,
+		InitCalled: object.InitCalled(),
+	}
+	if object.Module() != nil {
+		info.Module = object.Module().Path()
+	}
+	return info
+}
+
+// OverrideInfo describes an object replaced by Replace or removed by Unregister.
+type OverrideInfo struct {
+	Old ObjectInfo
+	// New is nil if the old object is unregistered.
+	New *ObjectInfo
+}
+
+// ListOverrides returns the replaced and unregistered objects in order.
+func ListOverrides() []OverrideInfo {
+	overrides := iocContainer.ListOverrides()
+
+	ret := make([]OverrideInfo, len(overrides))
+	for i, override := range overrides {
+		ret[i].Old = newObjectInfo(override.Old)
+		if override.New != nil {
+			info := newObjectInfo(override.New)
+			ret[i].New = &info
+		}
+	}
+	return ret
+}
+
+// Isolate replaces the global container with an isolated copy of the registered objects, value providers and values set at runtime,
+// and returns a function restoring the original container.
+// The objects of the copy are not built yet, and registrations in the copy do not affect the original.
+// It is intended for tests, see the ioctest package.
+// The global container is swapped without synchronization, so the isolated tests must not call t.Parallel.
+func Isolate() (restore func()) {
+	original := iocContainer
+	iocContainer = original.Clone()
+	return func() {
+		iocContainer = original
+	}
+}
+
+// AddValueProvider adds a value provider, the later added providers override the values of the earlier ones
+// unless ordered by ProviderPriority. The provider can be named by ProviderName and skipped on failure by ProviderOptional.
+// Example: `AddValueProvider(NewFileValueProvider("config-local.yaml"), ProviderName("local"), ProviderOptional(), ProviderPriority(10))`
+func AddValueProvider(provider ValueProvider, opts ...ProviderOption) error {
 	if provider == nil {
 		return errors.New("provider cannot be nil")
 	}
 
-	iocContainer.AddValueProvider(provider)
+	var options ioc.ProviderOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	iocContainer.AddValueProvider(provider, options)
 	return nil
 }
 
+// ValueProviderStatuses returns the statuses of the value providers at their last load ordered by priority,
+// including the errors of the skipped optional providers and the durations of the loads, eg. for diagnostics.
+func ValueProviderStatuses() []ProviderStatus {
+	return iocContainer.ProviderStatuses()
+}
+
+// SetMergeMode sets the mode of looking up the keys across the value providers.
+// By default, a key is looked up in the provider with the highest priority providing it,
+// so a struct bound from `db` gets the whole `db` map of one provider.
+// In the MergeDeep mode, the maps of the key are merged across all the providers,
+// and the lists are merged by the list strategy.
+// Example: `SetMergeMode(MergeDeep, ListAppend)`
+func SetMergeMode(mode MergeMode, listStrategy ListStrategy) {
+	iocContainer.SetMergeMode(mode, listStrategy)
+}
+
+// SetProfiles activates the profiles, overriding the profiles activated by
+// the environment variable `IOC_PROFILES_ACTIVE` or the value `ioc.profiles.active`.
+// The later profiles have higher priority.
+func SetProfiles(profiles ...string) {
+	iocContainer.SetProfiles(profiles)
+}
+
+// ActiveProfiles returns the active profiles.
+func ActiveProfiles() ([]string, error) {
+	return iocContainer.ActiveProfiles()
+}
+
+// Refresh reloads the values of the value providers and notifies the listeners added by OnValueChange,
+// the last loaded values are kept if any provider fails.
+func Refresh() error {
+	return iocContainer.RefreshValues()
+}
+
+// OnValueChange calls fn for each changed value whose key matches the pattern after the values are refreshed.
+// In the pattern, "*" matches a single key segment and "**" matches any number of key segments.
+// Example: `OnValueChange("app.feature.*", fn)`
+func OnValueChange(pattern string, fn func(change ValueChange)) (cancel func()) {
+	return iocContainer.OnValueChange(pattern, fn)
+}
+
+// Watch refreshes the values when the watchable value providers, like FileValueProvider and DirValueProvider, change.
+// Only the providers added before are watched.
+func Watch() (stop func()) {
+	return iocContainer.WatchValues()
+}
+
+// BindConfig binds the values of the prefix onto a struct by the names of its exported fields.
+// The names are matched relaxedly, so a field `MaxConns` is bound to `max_conns`, `max-conns` or `maxConns`,
+// the names of the yaml and json tags are used as fallbacks and the property tag overrides the name.
+// The nested structs, the pointers to structs and the structs in lists and maps are bound the same way.
+// Inject the bound struct by the config tag, eg. a field `db DBConfig` tagged `config:"app.db"`.
+// Example: `BindConfig[DBConfig]("app.db", BindStrict())`
+func BindConfig[T any](prefix string, opts ...BindOption) (*T, error) {
+	var options ioc.BindOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	value, err := iocContainer.BindConfig(prefix, reflect.PointerTo(getRefType[T]()), options)
+	if err != nil {
+		return nil, err
+	}
+	return value.(*T), nil
+}
+
+// ValidateValues resolves and validates the values injected into all the registered objects without building them,
+// eg. at startup. The values are validated by the constraints of their tags, like `min=1`, `max=10`, `oneof=a|b`,
+// `regex=^[a-z]+$` and `nonempty`, and by the Validate methods of the bound structs.
+// All the missing and invalid values are returned in one error naming their keys and sources.
+func ValidateValues() error {
+	return iocContainer.ValidateValues()
+}
+
+// SetValue sets the value of the key over the values of all the value providers, eg. from an admin endpoint,
+// the listeners added by OnValueChange are notified and the Value[T] handles read the new value.
+// The value set before is removed if the value is nil. It is safe to call concurrently.
+// Example: `SetValue("app.feature.login", false)`
+func SetValue(key string, value any) error {
+	return iocContainer.SetValue(key, value)
+}
+
+// WithValues sets the values of the keys like SetValue until restore is called, eg. in a test.
+// Example: `restore, _ := WithValues(map[string]any{"app.port": 9090}); defer restore()`
+func WithValues(values map[string]any) (restore func(), err error) {
+	return iocContainer.WithValues(values)
+}
+
+// ExplainValue returns the resolved value of the key with the providers providing it ordered by priority,
+// the file and the line of each provider are included if known, eg. for the YAML files of FileValueProvider.
+// The values of the secret keys are masked, see MaskValues.
+// Example: `fmt.Println(ExplainValue("app.port"))` prints `app.port = 8080 (file config-prod.yaml:2), overrides 80 (file config.yaml:5)`
+func ExplainValue(key string) (*ValueInfo, error) {
+	return iocContainer.ExplainValue(key)
+}
+
+// DumpValues returns the resolved values of all the keys with their origins sorted by key,
+// the values of the secret keys are masked, see MaskValues.
+func DumpValues() ([]*ValueInfo, error) {
+	return iocContainer.DumpValues()
+}
+
+// MaskValues masks the values of the keys matching the patterns in ExplainValue and DumpValues,
+// in addition to the keys containing password, secret, token, credential, apikey or privatekey.
+// In the pattern, "*" matches a single key segment or part of a segment and "**" matches any number of key segments.
+// Example: `MaskValues("app.db.dsn", "**.*_pin")`
+func MaskValues(patterns ...string) {
+	iocContainer.AddMaskPatterns(patterns...)
+}
+
 func GetValue[T any](key string) (T, bool, error) {
 	var defaultVal T
 	val, ok, err := iocContainer.GetValue(key, getRefType[T]())
@@ -1,9 +1,21 @@
 package ioc
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	ioc "github.com/sakuradon99/ioc/internal"
 	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type propertyStu struct {
@@ -156,6 +168,235 @@ type ObjectP struct {
 	iMap map[string]InterfaceMulti `inject:"*"`
 }
 
+type ObjectQ struct {
+	handlers []InterfaceMulti
+	aList    []*ObjectA
+	iMap     map[string]InterfaceMulti
+}
+
+func NewObjectQ(aList []*ObjectA, iMap map[string]InterfaceMulti, handlers ...InterfaceMulti) *ObjectQ {
+	return &ObjectQ{handlers: handlers, aList: aList, iMap: iMap}
+}
+
+type ObjectR struct {
+	sList []*ObjectS
+	sMap  map[string]*ObjectS
+	sArgs []*ObjectS
+}
+
+func NewObjectR(sList []*ObjectS, sMap map[string]*ObjectS, sArgs ...*ObjectS) *ObjectR {
+	return &ObjectR{sList: sList, sMap: sMap, sArgs: sArgs}
+}
+
+type ObjectS struct {
+	name string
+}
+
+type ObjectT struct {
+	s *ObjectS
+}
+
+type ObjectResult struct {
+	Out
+	s1 *ObjectS `provide:"s1;alias=alias1,alias2"`
+	s2 *ObjectS `provide:"s2"`
+	t  *ObjectT
+}
+
+func NewObjectResult(i Interface) (ObjectResult, error) {
+	s1 := &ObjectS{name: i.Test()}
+	return ObjectResult{
+		s1: s1,
+		s2: &ObjectS{name: "s2"},
+		t:  &ObjectT{s: s1},
+	}, nil
+}
+
+type ObjectU struct {
+	s *ObjectS `inject:"*"`
+}
+
+type ObjectV struct {
+	str       string   `value:"str"`
+	nextStr   string   `value:"next.str"`
+	nextInt   int      `value:"next.int"`
+	strArr    []string `value:"string_arr"`
+	strPtrArr []string `value:"string_ptr_arr"`
+	propStr   *string  `value:"property_stu.str_ptr"`
+}
+
+type ObjectW struct {
+	port    int        `value:"app.port;default=8080"`
+	hosts   []string   `value:"app.hosts;default=h1,h2"`
+	name    string     `value:"app.name;default=${str}"`
+	timeout *string    `value:"app.timeout;optional"`
+	db      *ObjectWDB `value:"db"`
+}
+
+type ObjectWDB struct {
+	host    string `property:"host;required"`
+	port    int    `property:"port;default=5432"`
+	timeout string `property:"timeout;default=5s"`
+}
+
+type ObjectX struct {
+	db ObjectWDB `value:"db"`
+}
+
+type ObjectXOptional struct {
+	defaults ObjectXDBDefaults `value:"missing_db;optional"`
+}
+
+type ObjectXOptionalRequired struct {
+	db ObjectWDB `value:"missing_db;optional"`
+}
+
+type ObjectXDBDefaults struct {
+	port    int    `property:"port;default=5432"`
+	timeout string `property:"timeout;default=5s"`
+}
+
+type ObjectY struct {
+	port  Value[int]      `value:"app.port;default=8080"`
+	name  *Value[string]  `value:"app.name"`
+	hosts Value[[]string] `value:"app.hosts;optional"`
+}
+
+type ObjectZLevel int
+
+func (l *ObjectZLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return errors.New("invalid level")
+	}
+	return nil
+}
+
+type ObjectZPriority int
+
+type ObjectZPoint struct {
+	x, y int
+}
+
+type ObjectZ struct {
+	timeout  time.Duration    `value:"types.timeout"`
+	start    time.Time        `value:"types.start"`
+	endpoint *url.URL         `value:"types.endpoint"`
+	ip       net.IP           `value:"types.ip"`
+	size     ByteSize         `value:"types.size"`
+	sizes    []ByteSize       `value:"types.sizes"`
+	level    ObjectZLevel     `value:"types.level"`
+	priority *ObjectZPriority `value:"types.priority"`
+	point    ObjectZPoint     `value:"types.point"`
+	any      any              `value:"types.priority"`
+}
+
+type ObjectMapEndpoint struct {
+	host string `property:"str"`
+	port int    `property:"int"`
+}
+
+type ObjectMapProps struct {
+	limits    map[string]int                `property:"limits"`
+	endpoints map[string]*ObjectMapEndpoint `property:"endpoints"`
+}
+
+type ObjectMap struct {
+	limits    map[string]int               `value:"limits"`
+	endpoints map[string]ObjectMapEndpoint `value:"endpoints"`
+	codes     map[int]string               `value:"codes"`
+	routes    []map[string][]string        `value:"routes"`
+	props     *ObjectMapProps              `value:"map_props"`
+}
+
+type ConfigPool struct {
+	MaxConns int
+	Idle     time.Duration `yaml:"idle_timeout"`
+}
+
+type ConfigBase struct {
+	Name string
+}
+
+type ConfigDB struct {
+	ConfigBase
+	Host     string
+	Port     int `property:"port;default=5432"`
+	Pool     *ConfigPool
+	Replicas []ConfigBase
+	Labels   map[string]string `json:"tags"`
+	Ignored  string            `yaml:"-"`
+	internal string
+}
+
+type ObjectConfig struct {
+	db ConfigDB `config:"app.db"`
+}
+
+type ConfigServer struct {
+	Host    string        `property:"host;nonempty"`
+	Port    int           `property:"port;min=1;max=65535"`
+	Mode    string        `property:"mode;oneof=debug|release"`
+	Timeout time.Duration `property:"timeout;max=1m"`
+	Name    string        `property:"name;regex=^[a-z]+$"`
+}
+
+func (s ConfigServer) Validate() error {
+	if s.Mode == "release" && s.Timeout == 0 {
+		return errors.New("timeout is required in release mode")
+	}
+	return nil
+}
+
+type ObjectValidation struct {
+	workers int          `value:"app.workers;min=1"`
+	server  ConfigServer `config:"app.server"`
+}
+
+// ConfigWorkerPool reads the values in its Validate method
+type ConfigWorkerPool struct {
+	Size int `property:"size;min=1"`
+}
+
+func (p ConfigWorkerPool) Validate() error {
+	workers, _, err := GetValue[int]("app.workers")
+	if err != nil {
+		return err
+	}
+	if p.Size < workers {
+		return errors.New("size is less than the workers")
+	}
+	return nil
+}
+
+type ObjectKeyPath struct {
+	path    string `value:"routes[1].path"`
+	appName string `value:"labels.\"app.kubernetes.io/name\""`
+}
+
+// profileValueProvider provides the values of its profiles, the other profiles are not found
+type profileValueProvider struct {
+	values   map[string]any
+	err      error
+	profiles map[string]map[string]any
+}
+
+func (p *profileValueProvider) Provide() (map[string]any, error) {
+	return p.values, p.err
+}
+
+func (p *profileValueProvider) ProfileProvider(profile string) ValueProvider {
+	values, ok := p.profiles[profile]
+	if !ok {
+		return &profileValueProvider{err: os.ErrNotExist}
+	}
+	return &profileValueProvider{values: values}
+}
+
 func Test_IOC_success(t *testing.T) {
 	t.Run("inject value", func(t *testing.T) {
 		iocContainer = ioc.NewContainerImpl()
@@ -463,6 +704,1195 @@ func Test_IOC_success(t *testing.T) {
 		assert.Equal(t, "test2", nameToInterface["multi2"].TestMulti())
 	})
 
+	t.Run("inject collections with constructor", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		Register[ImplMulti1](Name("multi1"))
+		Register[ImplMulti2](Name("multi2"))
+		Register[ObjectQ](Constructor(NewObjectQ))
+
+		q, err := GetObject[ObjectQ]("")
+		assert.Nil(t, err)
+		assert.NotNil(t, q)
+
+		assert.Equal(t, 2, len(q.handlers))
+		assert.ElementsMatch(t, []string{"test1", "test2"}, []string{q.handlers[0].TestMulti(), q.handlers[1].TestMulti()})
+		assert.Nil(t, q.aList)
+		assert.Equal(t, 2, len(q.iMap))
+		assert.Equal(t, "test1", q.iMap["multi1"].TestMulti())
+		assert.Equal(t, "test2", q.iMap["multi2"].TestMulti())
+
+		Provide(func() *ObjectS { return &ObjectS{name: "s1"} }, Name("s1"))
+		Provide(func() *ObjectS { return &ObjectS{name: "s2"} }, Name("s2"))
+		Register[ObjectR](Constructor(NewObjectR))
+
+		r, err := GetObject[ObjectR]("")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(r.sList))
+		assert.ElementsMatch(t, []string{"s1", "s2"}, []string{r.sList[0].name, r.sList[1].name})
+		assert.Equal(t, 2, len(r.sMap))
+		assert.Equal(t, "s1", r.sMap["s1"].name)
+		assert.Equal(t, "s2", r.sMap["s2"].name)
+		assert.Equal(t, 2, len(r.sArgs))
+		assert.ElementsMatch(t, []string{"s1", "s2"}, []string{r.sArgs[0].name, r.sArgs[1].name})
+		// the objects are shared by all the collections
+		assert.Same(t, r.sMap["s1"], r.sList[0])
+	})
+
+	t.Run("provide objects with result struct", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		Register[Impl]()
+		Provide(NewObjectResult)
+
+		s1, err := GetObject[ObjectS]("alias2")
+		assert.Nil(t, err)
+		assert.Equal(t, "test", s1.name)
+
+		nameToS, err := GetObjectMap[ObjectS]("*")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(nameToS))
+		assert.Equal(t, "s2", nameToS["s2"].name)
+
+		o, err := GetObject[ObjectT]("")
+		assert.Nil(t, err)
+		assert.Same(t, s1, o.s)
+
+		iocContainer = ioc.NewContainerImpl()
+		Provide(func() ObjectResult {
+			return ObjectResult{s1: &ObjectS{name: "s1"}, s2: &ObjectS{name: "s2"}}
+		})
+		// the objects are built on the first retrieval, so the nil field fails the container
+		_, err = GetObject[ObjectS]("s1")
+		assert.EqualError(t, err, "result field <t> of [ioc.ObjectResult] is nil")
+	})
+
+	t.Run("group objects with module", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		Module("app",
+			Module("billing",
+				Prefix("billing."),
+				Provide(func() *ObjectS { return &ObjectS{name: "billing"} }, Name("s"), Private()),
+				Register[ObjectU](Name("u")),
+			),
+			Module("shipping",
+				ModuleConditional("#condition.use_impl_multi == 1"),
+				Register[ImplMulti1](Name("multi")),
+			),
+			Register[ImplMulti2](Name("multi")),
+		)
+
+		u, err := GetObject[ObjectU]("billing.u")
+		assert.Nil(t, err)
+		assert.Equal(t, "billing", u.s.name)
+
+		_, err = GetObject[ObjectS]("billing.s")
+		assert.NotNil(t, err)
+
+		i, err := GetInterface[InterfaceMulti]("multi")
+		assert.Nil(t, err)
+		assert.Equal(t, "test2", i.TestMulti())
+
+		infos, err := ListObjects()
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(infos))
+		assert.Equal(t, "app/billing", infos[0].Module)
+		assert.True(t, infos[0].Private)
+		assert.Equal(t, "app", infos[2].Module)
+	})
+
+	t.Run("prefix names of nested modules", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		Register[ObjectS](Name("svc"))
+		Module("a",
+			Prefix("a."),
+			Register[ObjectS](Name("svc")),
+			Module("b",
+				Prefix("b."),
+				Register[ObjectS](Name("svc")),
+			),
+		)
+
+		nameToS, err := GetObjectMap[ObjectS]("*")
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(nameToS))
+		assert.NotNil(t, nameToS["svc"])
+		assert.NotNil(t, nameToS["a.svc"])
+		assert.NotNil(t, nameToS["a.b.svc"])
+
+		// the duplicates outside prefixed modules and the duplicate final names are reported immediately
+		assert.Panics(t, func() { Register[ObjectS](Name("a.svc")) })
+		assert.Panics(t, func() { Module("c", Register[ObjectS](Name("svc"))) })
+		assert.Panics(t, func() { Module("c", Prefix("a."), Register[ObjectS](Name("svc"))) })
+		assert.Panics(t, func() { Module("c", Prefix("c."), Register[ObjectS](Name("x")), Register[ObjectS](Name("x"))) })
+	})
+
+	t.Run("replace and unregister objects", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		Module("app", Register[ImplMulti1](Name("multi")))
+		Register[ImplMulti2](Name("multi2"))
+		Register[ImplMulti2](Name("multi"), ReplaceTarget[InterfaceMulti]())
+		Provide(func() *ImplMulti2 { return &ImplMulti2{} }, Name("multi2"), Replace())
+
+		assert.Panics(t, func() { Register[ImplMulti1](Name("missing"), Replace()) })
+		assert.Panics(t, func() { Register[ImplMulti1](Name("multi"), Replace()) })
+		assert.Panics(t, func() { Register[ObjectS](Name("multi2"), ReplaceTarget[InterfaceMulti]()) })
+		assert.NotNil(t, Unregister[ImplMulti2]("missing"))
+		assert.Nil(t, Unregister[ImplMulti2]("multi2"))
+
+		nameToI, err := GetInterfaceMap[InterfaceMulti]("*")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(nameToI))
+		assert.IsType(t, &ImplMulti2{}, nameToI["multi"])
+		assert.Equal(t, "test2", nameToI["multi"].TestMulti())
+
+		overrides := ListOverrides()
+		assert.Equal(t, 3, len(overrides))
+		assert.Equal(t, "multi", overrides[0].Old.Name)
+		assert.True(t, strings.HasSuffix(overrides[0].Old.Type, "ImplMulti1"))
+		assert.True(t, strings.HasSuffix(overrides[0].New.Type, "ImplMulti2"))
+		assert.Equal(t, "app", overrides[0].New.Module)
+		assert.Equal(t, "multi2", overrides[2].Old.Name)
+		assert.Nil(t, overrides[2].New)
+
+		Register[ImplMulti2](Name("multi3"))
+		assert.NotNil(t, Unregister[InterfaceMulti]("missing"))
+		assert.Nil(t, Unregister[InterfaceMulti]("multi3"))
+		assert.Equal(t, 4, len(ListOverrides()))
+	})
+
+	t.Run("inject value with env value provider", func(t *testing.T) {
+		t.Setenv("IOCTEST_NEXT_STR", "env_str")
+		t.Setenv("IOCTEST_NEXT_INT", "3")
+		t.Setenv("IOCTEST_STRING_ARR", "env_1, env_2")
+		t.Setenv("IOCTEST_STRING_PTR_ARR_1", "env_ptr_2")
+		t.Setenv("IOCTEST_STRING_PTR_ARR_0", "env_ptr_1")
+		t.Setenv("IOCTEST_PROPERTY_STU_STRPTR", "env_str_ptr")
+		t.Setenv("IOCTEST_SPARSE_1000000000", "sparse")
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		_ = AddValueProvider(NewEnvValueProvider(EnvPrefix("IOCTEST")))
+		Register[ObjectV]()
+
+		v, err := GetObject[ObjectV]("")
+		assert.Nil(t, err)
+		assert.Equal(t, "str", v.str)
+		assert.Equal(t, "env_str", v.nextStr)
+		assert.Equal(t, 3, v.nextInt)
+		assert.Equal(t, []string{"env_1", "env_2"}, v.strArr)
+		assert.Equal(t, []string{"env_ptr_1", "env_ptr_2"}, v.strPtrArr)
+		assert.Equal(t, "env_str_ptr", *v.propStr)
+
+		// the sparse indexes are kept as map keys
+		sparse, _, err := GetValue[map[string]string]("sparse")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"1000000000": "sparse"}, sparse)
+
+		// the keys of the other providers are matched exactly
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		_, found, err := GetValue[string]("NEXT.STR")
+		assert.Nil(t, err)
+		assert.False(t, found)
+
+		// all the variables are provided without the prefix
+		t.Setenv("STR", "env_no_prefix")
+		_ = AddValueProvider(NewEnvValueProvider())
+		str, _, err := GetValue[string]("str")
+		assert.Nil(t, err)
+		assert.Equal(t, "env_no_prefix", str)
+	})
+
+	t.Run("inject value with flag value provider", func(t *testing.T) {
+		t.Setenv("IOCTEST_NEXT_STR", "env_str")
+		t.Setenv("IOCTEST_NEXT_INT", "3")
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.Int("next.int", 0, "")
+		_ = fs.Parse([]string{"--next.int=4"})
+
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		_ = AddValueProvider(NewEnvValueProvider(EnvPrefix("IOCTEST")))
+		_ = AddValueProvider(NewFlagValueProvider([]string{
+			"run", "--next.str", "flag_str", "--string_arr=flag_1", "--string_arr=flag_2", "--", "--str=ignored",
+		}))
+		_ = AddValueProvider(NewFlagSetValueProvider(fs))
+		Register[ObjectV]()
+
+		v, err := GetObject[ObjectV]("")
+		assert.Nil(t, err)
+		assert.Equal(t, "str", v.str)
+		assert.Equal(t, "flag_str", v.nextStr)
+		assert.Equal(t, 4, v.nextInt)
+		assert.Equal(t, []string{"flag_1", "flag_2"}, v.strArr)
+	})
+
+	t.Run("inject value with file formats", func(t *testing.T) {
+		for _, file := range []string{"testdata/config.toml", "testdata/config.properties", "testdata/config.ini"} {
+			iocContainer = ioc.NewContainerImpl()
+			_ = AddValueProvider(NewFileValueProvider(file))
+			Register[ObjectA]()
+
+			a, err := GetObject[ObjectA]("")
+			assert.Nil(t, err, file)
+			assert.Equal(t, "str", a.str, file)
+			assert.Equal(t, "str_ptr", *a.strPtr, file)
+			assert.Equal(t, 1, a.int, file)
+			assert.Equal(t, float32(0.99), a.float, file)
+			assert.True(t, a.bool, file)
+			assert.Equal(t, []string{"str_1", "str_2"}, a.strArr, file)
+			assert.Equal(t, "str_ptr_2", *a.strPtrArr[1], file)
+			assert.Equal(t, "next_str", a.nextStr, file)
+			assert.Equal(t, 2, a.nextInt, file)
+			assert.Equal(t, []string{"str_1", "str_2"}, a.propertyStu.strArr, file)
+			assert.Equal(t, "str_2", a.nestedStu.str2, file)
+			assert.Equal(t, "str_ptr", *a.nestedStu.strPtr, file)
+		}
+
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.env"))
+		Register[ObjectN]()
+
+		n, err := GetObject[ObjectN]("")
+		assert.Nil(t, err)
+		assert.Equal(t, "value1", n.key1)
+		assert.Equal(t, "value2", n.key2)
+		assert.Equal(t, "next_value1", n.nextKey1)
+
+		// the keys of dotenv files are matched relaxedly like the environment variables
+		maxConns, ok, err := GetValue[int]("app.db.max_conns")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 5, maxConns)
+	})
+
+	t.Run("get value with custom decoder", func(t *testing.T) {
+		RegisterValueDecoder(".custom", func(content []byte) (map[string]any, error) {
+			return map[string]any{"custom": strings.TrimSpace(string(content))}, nil
+		})
+		file := filepath.Join(t.TempDir(), "config.custom")
+		_ = os.WriteFile(file, []byte("custom_value\n"), 0o644)
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider(file))
+
+		v, ok, err := GetValue[string]("custom")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "custom_value", v)
+	})
+
+	t.Run("get value with dir value provider", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.Nil(t, os.MkdirAll(filepath.Join(dir, "..2024_01_01", "db"), 0o755))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "..2024_01_01", "db.password"), []byte("secret\n"), 0o644))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "..2024_01_01", "db", "host"), []byte("localhost\r\n"), 0o644))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "..2024_01_01", "app.yaml"), []byte("name: app\n"), 0o644))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "..2024_01_01", ".hidden"), []byte("hidden"), 0o644))
+		assert.Nil(t, os.Symlink("..2024_01_01", filepath.Join(dir, "..data")))
+		for _, name := range []string{"db.password", "db", "app.yaml", ".hidden"} {
+			assert.Nil(t, os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)))
+		}
+
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewDirValueProvider(dir, DirParseFiles(), DirIgnoreDotFiles()))
+
+		password, _, err := GetValue[string]("db.password")
+		assert.Nil(t, err)
+		assert.Equal(t, "secret", password)
+		host, _, err := GetValue[string]("db.host")
+		assert.Nil(t, err)
+		assert.Equal(t, "localhost", host)
+		name, _, err := GetValue[string]("app.name")
+		assert.Nil(t, err)
+		assert.Equal(t, "app", name)
+		_, ok, err := GetValue[string]("hidden")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		// the file `db.host` conflicts with the file `db/host`
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "db.host"), []byte("remote"), 0o644))
+		_, err = NewDirValueProvider(dir).Provide()
+		assert.NotNil(t, err)
+		// the parsed file `app.yaml` conflicts with the file `app.name`
+		assert.Nil(t, os.Remove(filepath.Join(dir, "db.host")))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "app.name"), []byte("other"), 0o644))
+		_, err = NewDirValueProvider(dir, DirParseFiles()).Provide()
+		assert.NotNil(t, err)
+		_, err = NewDirValueProvider(dir).Provide()
+		assert.Nil(t, err)
+
+		// the symlink to a parent directory is reported instead of followed forever
+		loopDir := t.TempDir()
+		assert.Nil(t, os.MkdirAll(filepath.Join(loopDir, "db"), 0o755))
+		assert.Nil(t, os.Symlink("..", filepath.Join(loopDir, "db", "parent")))
+		_, err = NewDirValueProvider(loopDir).Provide()
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "is a symlink loop")
+	})
+
+	t.Run("activate profiles", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{"ioc": map[string]any{"profiles": map[string]any{"active": "dev"}}}))
+		Register[ObjectS](Name("dev"), Profile("dev"))
+		Register[ObjectS](Name("prod"), Profile("prod"))
+		Register[ObjectS](Name("not_prod"), Profile("!prod"))
+		Register[ObjectS](Name("prod_multi"), Profile("prod"), Conditional("#condition.use_impl_multi == 2"))
+		Register[ObjectV]()
+
+		nameToS, err := GetObjectMap[ObjectS]("*")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(nameToS))
+		assert.NotNil(t, nameToS["dev"])
+		assert.NotNil(t, nameToS["not_prod"])
+
+		t.Setenv("IOC_PROFILES_ACTIVE", "staging,prod")
+		iocContainer = iocContainer.Clone()
+		profiles, err := ActiveProfiles()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"staging", "prod"}, profiles)
+		nameToS, err = GetObjectMap[ObjectS]("*")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(nameToS))
+		assert.NotNil(t, nameToS["prod"])
+		assert.NotNil(t, nameToS["prod_multi"])
+		v, err := GetObject[ObjectV]("")
+		assert.Nil(t, err)
+		assert.Equal(t, "prod_str", v.str)
+		assert.Equal(t, "next_str", v.nextStr)
+		assert.Equal(t, 3, v.nextInt)
+
+		SetProfiles("dev")
+		profiles, err = ActiveProfiles()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"dev"}, profiles)
+
+		// the profiles can be activated at runtime
+		t.Setenv("IOC_PROFILES_ACTIVE", "")
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		str, _, _ := GetValue[string]("str")
+		assert.Equal(t, "str", str)
+		assert.Nil(t, SetValue("ioc.profiles.active", "prod"))
+		profiles, err = ActiveProfiles()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"prod"}, profiles)
+		str, _, _ = GetValue[string]("str")
+		assert.Equal(t, "prod_str", str)
+
+		// the names of the profiles are escaped in the conditions
+		iocContainer = ioc.NewContainerImpl()
+		SetProfiles(`it's`, `a\b`)
+		Register[ObjectS](Name("quoted"), Profile(`it's`))
+		Register[ObjectS](Name("backslash"), Profile(`a\b`))
+		Register[ObjectS](Name("other"), Profile(`it`))
+		nameToS, err = GetObjectMap[ObjectS]("*")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(nameToS))
+		assert.NotNil(t, nameToS["quoted"])
+		assert.NotNil(t, nameToS["backslash"])
+	})
+
+	t.Run("get value with placeholders", func(t *testing.T) {
+		t.Setenv("IOCTEST_DB_HOST", "env_host")
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{
+			"db": map[string]any{
+				"host":  "localhost",
+				"url":   "postgres://${db.host}:${db.port:5432}/${db.name:${app.name}}",
+				"hosts": "${db.replicas}",
+			},
+			"app":     map[string]any{"name": "app"},
+			"escaped": `\${db.host}`,
+			"cycle":   map[string]any{"a": "${cycle.b}", "b": "x${cycle.a}"},
+		}))
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{
+			"db": map[string]any{"replicas": []any{"r1", "r2"}},
+		}))
+		_ = AddValueProvider(NewEnvValueProvider(EnvPrefix("IOCTEST")))
+
+		url, _, err := GetValue[string]("db.url")
+		assert.Nil(t, err)
+		assert.Equal(t, "postgres://env_host:5432/app", url)
+		hosts, _, err := GetValue[[]string]("db.hosts")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"r1", "r2"}, hosts)
+		escaped, _, err := GetValue[string]("escaped")
+		assert.Nil(t, err)
+		assert.Equal(t, "${db.host}", escaped)
+		_, _, err = GetValue[string]("cycle.a")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("inject value with defaults", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{"db": map[string]any{"host": "localhost", "timeout": "1s"}}))
+		Register[ObjectW]()
+
+		w, err := GetObject[ObjectW]("")
+		assert.Nil(t, err)
+		assert.Equal(t, 8080, w.port)
+		assert.Equal(t, []string{"h1", "h2"}, w.hosts)
+		assert.Equal(t, "str", w.name)
+		assert.Nil(t, w.timeout)
+		assert.Equal(t, "localhost", w.db.host)
+		assert.Equal(t, 5432, w.db.port)
+		assert.Equal(t, "1s", w.db.timeout)
+
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{"db": map[string]any{"port": 1}}))
+		Register[ObjectX]()
+
+		_, err = GetObject[ObjectX]("")
+		assert.EqualError(t, err, "missing value <db.host>")
+
+		// the property defaults are applied to the missing optional struct
+		iocContainer = ioc.NewContainerImpl()
+		Register[ObjectXOptional]()
+		x, err := GetObject[ObjectXOptional]("")
+		assert.Nil(t, err)
+		assert.Equal(t, 5432, x.defaults.port)
+		assert.Equal(t, "5s", x.defaults.timeout)
+
+		// the required properties of the missing optional struct are still required
+		iocContainer = ioc.NewContainerImpl()
+		Register[ObjectXOptionalRequired]()
+		_, err = GetObject[ObjectXOptionalRequired]("")
+		assert.EqualError(t, err, "missing value <missing_db.host>")
+	})
+
+	t.Run("refresh and watch values", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "config.yaml")
+		_ = os.WriteFile(file, []byte("app:\n  feature:\n    login: false\n  name: app\n"), 0o644)
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider(file, FileWatchInterval(10*time.Millisecond)))
+		_, _, _ = GetValue[string]("app.name")
+
+		var changes []ValueChange
+		cancel := OnValueChange("app.feature.*", func(change ValueChange) {
+			changes = append(changes, change)
+		})
+		_ = os.WriteFile(file, []byte("app:\n  feature:\n    login: true\n    signup: true\n  name: app2\n"), 0o644)
+		assert.Nil(t, Refresh())
+		assert.Equal(t, []ValueChange{
+			{Key: "app.feature.login", Old: false, New: true},
+			{Key: "app.feature.signup", New: true},
+		}, changes)
+		name, _, _ := GetValue[string]("app.name")
+		assert.Equal(t, "app2", name)
+		cancel()
+
+		_ = os.WriteFile(file, []byte("app: ["), 0o644)
+		assert.NotNil(t, Refresh())
+		name, _, _ = GetValue[string]("app.name")
+		assert.Equal(t, "app2", name)
+
+		changed := make(chan ValueChange, 1)
+		OnValueChange("app.**", func(change ValueChange) {
+			changed <- change
+		})
+		stop := Watch()
+		defer stop()
+		_ = os.WriteFile(file, []byte("app:\n  feature:\n    login: true\n    signup: true\n  name: watched\n"), 0o644)
+		select {
+		case change := <-changed:
+			assert.Equal(t, ValueChange{Key: "app.name", Old: "app2", New: "watched"}, change)
+		case <-time.After(time.Second):
+			t.Fatal("value change not notified")
+		}
+	})
+
+	t.Run("inject dynamic values", func(t *testing.T) {
+		values := map[string]any{"app": map[string]any{"name": "app"}}
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewMapValueProvider(values))
+		Register[ObjectY]()
+
+		y, err := GetObject[ObjectY]("")
+		assert.Nil(t, err)
+		assert.Equal(t, 8080, y.port.Get())
+		assert.Equal(t, "app", y.name.Get())
+		assert.Nil(t, y.hosts.Get())
+		assert.Nil(t, y.port.Validate(func(port int) error {
+			if port <= 0 {
+				return errors.New("invalid port")
+			}
+			return nil
+		}))
+		var oldName, newName string
+		y.name.OnChange(func(old string, new string) {
+			oldName, newName = old, new
+		})
+
+		values["app"] = map[string]any{"name": "app2", "port": 9090, "hosts": "h1,h2"}
+		assert.Nil(t, Refresh())
+		assert.Equal(t, 9090, y.port.Get())
+		assert.Equal(t, "app2", y.name.Get())
+		assert.Equal(t, []string{"h1", "h2"}, y.hosts.Get())
+		assert.Equal(t, "app", oldName)
+		assert.Equal(t, "app2", newName)
+
+		values["app"] = map[string]any{"name": "app2", "port": -1}
+		assert.Nil(t, Refresh())
+		assert.Equal(t, 9090, y.port.Get())
+		assert.EqualError(t, y.port.Err(), "invalid port")
+
+		port, err := BindValue[int]("app.port")
+		assert.Nil(t, err)
+		assert.Equal(t, -1, port.Get())
+		_, err = BindValue[int]("app.missing")
+		assert.EqualError(t, err, "missing value <app.missing>")
+
+		// the value is reloaded once per refresh, and no longer after it is closed
+		app, err := BindValue[map[string]any]("app")
+		assert.Nil(t, err)
+		reloads := 0
+		assert.Nil(t, app.Validate(func(map[string]any) error {
+			reloads++
+			return nil
+		}))
+		values["app"] = map[string]any{"name": "app3", "port": 1, "hosts": "h3"}
+		assert.Nil(t, Refresh())
+		assert.Equal(t, 2, reloads)
+		assert.Equal(t, "app3", app.Get()["name"])
+		app.Close()
+		values["app"] = map[string]any{"name": "app4"}
+		assert.Nil(t, Refresh())
+		assert.Equal(t, 2, reloads)
+		assert.Equal(t, "app3", app.Get()["name"])
+	})
+
+	t.Run("inject value with typed converters", func(t *testing.T) {
+		t.Cleanup(RegisterConverter(func(property any) (ObjectZPoint, error) {
+			var p ObjectZPoint
+			_, err := fmt.Sscanf(fmt.Sprint(property), "%d,%d", &p.x, &p.y)
+			return p, err
+		}))
+		values := map[string]any{"types": map[string]any{
+			"timeout":  "1m30s",
+			"start":    "2024-01-02T03:04:05Z",
+			"endpoint": "https://example.com:8443/api",
+			"ip":       "10.0.0.1",
+			"size":     "10MB",
+			"sizes":    []any{"1KiB", 2048, "1.5K"},
+			"level":    "info",
+			"priority": 3,
+			"point":    "1,2",
+		}}
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewMapValueProvider(values))
+		Register[ObjectZ]()
+
+		z, err := GetObject[ObjectZ]("")
+		assert.Nil(t, err)
+		assert.Equal(t, 90*time.Second, z.timeout)
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), z.start)
+		assert.Equal(t, "example.com:8443", z.endpoint.Host)
+		assert.Equal(t, "10.0.0.1", z.ip.String())
+		assert.Equal(t, ByteSize(10_000_000), z.size)
+		assert.Equal(t, []ByteSize{1024, 2048, 1536}, z.sizes)
+		assert.Equal(t, ObjectZLevel(1), z.level)
+		assert.Equal(t, ObjectZPriority(3), *z.priority)
+		assert.Equal(t, ObjectZPoint{x: 1, y: 2}, z.point)
+		assert.Equal(t, 3, z.any)
+
+		values["types"].(map[string]any)["level"] = "trace"
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewMapValueProvider(values))
+		_, _, err = GetValue[ObjectZLevel]("types.level")
+		assert.EqualError(t, err, "convert value <types.level> to [ioc.ObjectZLevel] failed, err=invalid level")
+
+		// the replaced builtin converter is restored
+		restore := RegisterConverter(func(property any) (time.Duration, error) {
+			return time.Hour, nil
+		})
+		timeout, _, err := GetValue[time.Duration]("types.timeout")
+		assert.Nil(t, err)
+		assert.Equal(t, time.Hour, timeout)
+		restore()
+		timeout, _, err = GetValue[time.Duration]("types.timeout")
+		assert.Nil(t, err)
+		assert.Equal(t, 90*time.Second, timeout)
+	})
+
+	t.Run("inject map value", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		Register[ObjectMap]()
+
+		m, err := GetObject[ObjectMap]("")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]int{"read": 100, "write": 10}, m.limits)
+		assert.Equal(t, map[string]ObjectMapEndpoint{
+			"users":  {host: "users.internal", port: 8080},
+			"orders": {host: "orders.internal", port: 8081},
+		}, m.endpoints)
+		assert.Equal(t, map[int]string{404: "not_found", 500: "internal_error"}, m.codes)
+		assert.Equal(t, []map[string][]string{
+			{"path": {"/users"}, "methods": {"GET", "POST"}},
+			{"path": {"/orders"}, "methods": {"GET"}},
+		}, m.routes)
+		assert.Equal(t, map[string]int{"read": 1}, m.props.limits)
+		assert.Equal(t, 8081, m.props.endpoints["orders"].port)
+	})
+
+	t.Run("bind config", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{"app": map[string]any{"db": map[string]any{
+			"name": "main",
+			"HOST": "localhost",
+			"pool": map[string]any{"max-conns": 10, "idle_timeout": "1m"},
+			"replicas": []any{
+				map[string]any{"name": "r1"},
+				map[string]any{"name": "r2"},
+			},
+			"tags":     map[string]any{"env": "dev"},
+			"ignored":  "ignored",
+			"internal": "internal",
+		}}}))
+		Register[ObjectConfig]()
+
+		o, err := GetObject[ObjectConfig]("")
+		assert.Nil(t, err)
+		assert.Equal(t, ConfigDB{
+			ConfigBase: ConfigBase{Name: "main"},
+			Host:       "localhost",
+			Port:       5432,
+			Pool:       &ConfigPool{MaxConns: 10, Idle: time.Minute},
+			Replicas:   []ConfigBase{{Name: "r1"}, {Name: "r2"}},
+			Labels:     map[string]string{"env": "dev"},
+		}, o.db)
+
+		db, err := BindConfig[ConfigDB]("app.db")
+		assert.Nil(t, err)
+		assert.Equal(t, "localhost", db.Host)
+		_, err = BindConfig[ConfigDB]("app.db", BindStrict())
+		assert.EqualError(t, err, "unknown config keys of <app.db> [app.db.ignored app.db.internal]")
+	})
+
+	t.Run("validate values", func(t *testing.T) {
+		values := map[string]any{"app": map[string]any{
+			"workers": 0,
+			"server": map[string]any{
+				"host": "",
+				"port": 70000,
+				"mode": "release",
+				"name": "Server1",
+			},
+		}}
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewMapValueProvider(values))
+		Register[ObjectValidation]()
+
+		err := ValidateValues()
+		assert.EqualError(t, err, "invalid values ["+
+			"<app.workers> must be at least 1 (map); "+
+			"<app.server.host> must not be empty (map); "+
+			"<app.server.port> must be at most 65535 (map); "+
+			"<app.server.name> must match ^[a-z]+$ (map); "+
+			"<app.server> timeout is required in release mode (map)]")
+		_, err = GetObject[ObjectValidation]("")
+		assert.NotNil(t, err)
+
+		values["app"] = map[string]any{
+			"workers": 2,
+			"server":  map[string]any{"host": "localhost", "port": 8080, "mode": "debug", "timeout": "30s", "name": "server"},
+		}
+		assert.Nil(t, Refresh())
+		assert.Nil(t, ValidateValues())
+		v, err := GetObject[ObjectValidation]("")
+		assert.Nil(t, err)
+		assert.Equal(t, 8080, v.server.Port)
+	})
+
+	t.Run("validate values reading values", func(t *testing.T) {
+		values := map[string]any{"app": map[string]any{
+			"workers": 4,
+			"pool":    map[string]any{"size": 2},
+		}}
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewMapValueProvider(values))
+
+		_, err := BindValue[ConfigWorkerPool]("app.pool")
+		assert.EqualError(t, err, "invalid values [<app.pool> size is less than the workers (map)]")
+
+		values["app"].(map[string]any)["workers"] = 1
+		assert.Nil(t, Refresh())
+		pool, err := BindValue[ConfigWorkerPool]("app.pool")
+		assert.Nil(t, err)
+		defer pool.Close()
+		assert.Equal(t, 2, pool.Get().Size)
+	})
+
+	t.Run("get value with deep merge", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{"db": map[string]any{
+			"host":    "localhost",
+			"port":    5432,
+			"options": map[string]any{"ssl": false, "max_conns": 10},
+			"hosts":   []any{"h1", "h2"},
+			"shards":  []any{map[string]any{"name": "s1", "weight": 1}},
+		}}))
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{"db": map[string]any{
+			"host":    "db.internal",
+			"options": map[string]any{"max_conns": 20},
+			"hosts":   []any{"h3"},
+			"shards":  []any{map[string]any{"weight": 2}, map[string]any{"name": "s2"}},
+		}}))
+
+		db, _, err := GetValue[map[string]any]("db")
+		assert.Nil(t, err)
+		assert.Nil(t, db["port"])
+
+		SetMergeMode(MergeDeep, ListReplace)
+		db, _, err = GetValue[map[string]any]("db")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]any{
+			"host":    "db.internal",
+			"port":    5432,
+			"options": map[string]any{"ssl": false, "max_conns": 20},
+			"hosts":   []any{"h3"},
+			"shards":  []any{map[string]any{"weight": 2}, map[string]any{"name": "s2"}},
+		}, db)
+
+		SetMergeMode(MergeDeep, ListAppend)
+		hosts, _, err := GetValue[[]string]("db.hosts")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"h1", "h2", "h3"}, hosts)
+
+		SetMergeMode(MergeDeep, ListMergeByIndex)
+		shards, _, err := GetValue[[]map[string]any]("db.shards")
+		assert.Nil(t, err)
+		assert.Equal(t, []map[string]any{{"name": "s1", "weight": 2}, {"name": "s2"}}, shards)
+
+		// the keys of the environment variables are matched relaxedly
+		t.Setenv("IOCTEST_DB_OPTIONS_MAXCONNS", "30")
+		_ = AddValueProvider(NewEnvValueProvider(EnvPrefix("IOCTEST")))
+		options, _, err := GetValue[map[string]any]("db.options")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]any{"ssl": false, "max_conns": "30"}, options)
+	})
+
+	t.Run("explain values", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{
+			"db": map[string]any{
+				"user":        "${str}",
+				"password":    "pa55",
+				"dsn":         "user:pa55@db",
+				"credentials": map[string]any{"key": "k"},
+			},
+		}))
+		SetProfiles("prod")
+
+		info, err := ExplainValue("next.int")
+		assert.Nil(t, err)
+		assert.Equal(t, 3, info.Value)
+		assert.Equal(t, []ValueOrigin{
+			{Source: "file testdata/config-prod.yaml", File: "testdata/config-prod.yaml", Line: 3, Value: 3},
+			{Source: "file testdata/config.yaml", File: "testdata/config.yaml", Line: 14, Value: 2},
+		}, info.Origins)
+		assert.Equal(t, "next.int = 3 (file testdata/config-prod.yaml:3), overrides 2 (file testdata/config.yaml:14)", info.String())
+
+		info, err = ExplainValue("db.user")
+		assert.Nil(t, err)
+		assert.Equal(t, "db.user = prod_str (map)", info.String())
+
+		info, err = ExplainValue("db")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]any{
+			"user":        "prod_str",
+			"password":    "******",
+			"dsn":         "user:pa55@db",
+			"credentials": "******",
+		}, info.Value)
+
+		_, err = ExplainValue("db.missing")
+		assert.Equal(t, "missing value <db.missing>", err.Error())
+
+		MaskValues("db.dsn")
+		infos, err := DumpValues()
+		assert.Nil(t, err)
+		keyToInfo := make(map[string]*ValueInfo)
+		for _, info := range infos {
+			keyToInfo[info.Key] = info
+		}
+		assert.Equal(t, "db.password = ****** (map)", keyToInfo["db.password"].String())
+		assert.Equal(t, "db.dsn = ****** (map)", keyToInfo["db.dsn"].String())
+		assert.Equal(t, "str = prod_str (file testdata/config-prod.yaml:1), overrides str (file testdata/config.yaml:1)", keyToInfo["str"].String())
+	})
+
+	t.Run("get value with key paths", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{"owner": `${labels."team:owner":none}`}))
+		Register[ObjectKeyPath](Conditional(`#routes[0].methods[1] == 'POST' && #labels."app.kubernetes.io/name" == 'ioc'`))
+
+		kp, err := GetObject[ObjectKeyPath]("")
+		assert.Nil(t, err)
+		assert.Equal(t, "/orders", kp.path)
+		assert.Equal(t, "ioc", kp.appName)
+
+		methods, ok, err := GetValue[[]string]("routes[0].methods")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"GET", "POST"}, methods)
+		_, ok, err = GetValue[string]("routes[2].path")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		owner, _, err := GetValue[string]("owner")
+		assert.Nil(t, err)
+		assert.Equal(t, "core", owner)
+
+		info, err := ExplainValue("routes[1].path")
+		assert.Nil(t, err)
+		assert.Equal(t, "routes[1].path = /orders (file testdata/config.yaml:60)", info.String())
+		infos, err := DumpValues()
+		assert.Nil(t, err)
+		var keys []string
+		for _, info := range infos {
+			if strings.HasPrefix(info.Key, "labels.") {
+				keys = append(keys, info.Key)
+			}
+		}
+		assert.Equal(t, []string{`labels."app.kubernetes.io/name"`, `labels."team:owner"`}, keys)
+	})
+
+	t.Run("get encrypted value", func(t *testing.T) {
+		key, err := GenerateAESKey()
+		assert.Nil(t, err)
+		t.Setenv("IOCTEST_ENCRYPT_KEY", key)
+		cipher, err := NewAESCipherFromEnv("IOCTEST_ENCRYPT_KEY")
+		assert.Nil(t, err)
+		RegisterDecryptor(EncryptedValueName, cipher)
+		t.Cleanup(func() {
+			RegisterDecryptor(EncryptedValueName, nil)
+		})
+
+		encrypted, err := cipher.Encrypt("s3cr3t")
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(encrypted, "ENC("))
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{
+			"db": map[string]any{
+				"pin": encrypted,
+				"url": "postgres://app:${db.pin}@db",
+			},
+			"bad": "ENC(aW52YWxpZA==)",
+		}))
+
+		pin, _, err := GetValue[string]("db.pin")
+		assert.Nil(t, err)
+		assert.Equal(t, "s3cr3t", pin)
+		url, _, err := GetValue[string]("db.url")
+		assert.Nil(t, err)
+		assert.Equal(t, "postgres://app:s3cr3t@db", url)
+		_, _, err = GetValue[string]("bad")
+		assert.Equal(t, "decrypt value of <ENC> failed, err=ciphertext too short", err.Error())
+
+		info, err := ExplainValue("db")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]any{"pin": "******", "url": "postgres://app:******@db"}, info.Value)
+		infos, err := DumpValues()
+		assert.Nil(t, err)
+		for _, info := range infos {
+			assert.NotContains(t, fmt.Sprint(info), "s3cr3t")
+		}
+	})
+
+	t.Run("set values at runtime", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		var changes []ValueChange
+		OnValueChange("next.*", func(change ValueChange) {
+			changes = append(changes, change)
+		})
+
+		err := SetValue("next.int", 5)
+		assert.Nil(t, err)
+		nextInt, _, err := GetValue[int]("next.int")
+		assert.Nil(t, err)
+		assert.Equal(t, 5, nextInt)
+		next, _, err := GetValue[map[string]string]("next")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"str": "next_str", "int": "5"}, next)
+		assert.Equal(t, []ValueChange{{Key: "next.int", Old: 2, New: 5}}, changes)
+		info, err := ExplainValue("next.int")
+		assert.Nil(t, err)
+		assert.Equal(t, "next.int = 5 (runtime), overrides 2 (file testdata/config.yaml:14)", info.String())
+
+		restore, err := WithValues(map[string]any{"next.int": 7, "next.str": "scoped"})
+		assert.Nil(t, err)
+		nextStr, _, _ := GetValue[string]("next.str")
+		assert.Equal(t, "scoped", nextStr)
+		restore()
+		restore()
+		nextInt, _, _ = GetValue[int]("next.int")
+		assert.Equal(t, 5, nextInt)
+		nextStr, _, _ = GetValue[string]("next.str")
+		assert.Equal(t, "next_str", nextStr)
+
+		err = SetValue("next.int", nil)
+		assert.Nil(t, err)
+		nextInt, _, _ = GetValue[int]("next.int")
+		assert.Equal(t, 2, nextInt)
+		err = SetValue("routes[0].path", "/")
+		assert.Equal(t, "unsupported value key <routes[0].path>, the elements of lists can't be set", err.Error())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_ = SetValue(fmt.Sprintf("concurrent.k%d", i), i)
+				_, _, _ = GetValue[int]("concurrent.k0")
+			}(i)
+		}
+		wg.Wait()
+		concurrent, _, err := GetValue[map[string]int]("concurrent")
+		assert.Nil(t, err)
+		assert.Equal(t, 10, len(concurrent))
+	})
+
+	t.Run("access values concurrently with refresh", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		Register[ObjectA]()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				// the watchers refresh the values while the values are used and changed
+				assert.Nil(t, Refresh())
+				SetMergeMode(MergeDeep, ListReplace)
+				assert.Nil(t, SetValue(fmt.Sprintf("concurrent.k%d", i), i))
+				_ = AddValueProvider(NewMapValueProvider(map[string]any{"map": i}), ProviderPriority(-1))
+				_, err := ExplainValue("str")
+				assert.Nil(t, err)
+				_, _ = DumpValues()
+				_, err = ActiveProfiles()
+				assert.Nil(t, err)
+				_ = ValueProviderStatuses()
+				str, _, err := GetValue[string]("str")
+				assert.Nil(t, err)
+				assert.Equal(t, "str", str)
+				_, err = GetObject[ObjectA]("")
+				assert.Nil(t, err)
+			}(i)
+		}
+		wg.Wait()
+	})
+
+	t.Run("isolate values set at runtime", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		assert.Nil(t, SetValue("next.int", 5))
+
+		restore := Isolate()
+		nextInt, _, err := GetValue[int]("next.int")
+		assert.Nil(t, err)
+		assert.Equal(t, 5, nextInt)
+		assert.Nil(t, SetValue("next.int", 7))
+		assert.Nil(t, SetValue("next.str", "isolated"))
+		restore()
+
+		nextInt, _, _ = GetValue[int]("next.int")
+		assert.Equal(t, 5, nextInt)
+		nextStr, _, _ := GetValue[string]("next.str")
+		assert.Equal(t, "next_str", nextStr)
+	})
+
+	t.Run("add value providers with options", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{"str": "defaults"}), ProviderName("defaults"), ProviderPriority(-1))
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{"str": "override", "int": 9}), ProviderName("override"), ProviderPriority(10))
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		_ = AddValueProvider(NewFileValueProvider("testdata/missing.yaml"), ProviderName("local"), ProviderOptional())
+		Register[ObjectS](Conditional("#int == 9"))
+
+		s, err := GetObject[ObjectS]("")
+		assert.Nil(t, err)
+		assert.NotNil(t, s)
+		info, err := ExplainValue("str")
+		assert.Nil(t, err)
+		assert.Equal(t, "str = override (override), overrides str (file testdata/config.yaml:1), defaults (defaults)", info.String())
+
+		statuses := ValueProviderStatuses()
+		assert.Equal(t, 4, len(statuses))
+		names := make([]string, len(statuses))
+		for i, status := range statuses {
+			names[i] = status.Name
+			assert.False(t, status.LoadedAt.IsZero())
+		}
+		assert.Equal(t, []string{"defaults", "file testdata/config.yaml", "local", "override"}, names)
+		assert.True(t, statuses[2].Optional)
+		assert.True(t, os.IsNotExist(statuses[2].Err))
+		assert.Nil(t, statuses[1].Err)
+
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/missing.yaml"))
+		_, _, err = GetValue[string]("str")
+		assert.True(t, os.IsNotExist(errors.Unwrap(err)))
+		assert.True(t, strings.HasPrefix(err.Error(), "load values of <file testdata/missing.yaml> failed"))
+
+		// only the missing sources of the optional providers are skipped
+		file := filepath.Join(t.TempDir(), "local.yaml")
+		assert.Nil(t, os.WriteFile(file, []byte("str: [unclosed\n"), 0o644))
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
+		_ = AddValueProvider(NewFileValueProvider(file), ProviderName("local"), ProviderOptional())
+		_, _, err = GetValue[string]("str")
+		assert.NotNil(t, err)
+		assert.True(t, strings.HasPrefix(err.Error(), "load values of <file "+file+"> failed"))
+		assert.NotNil(t, ValueProviderStatuses()[1].Err)
+	})
+
+	t.Run("skip profile variants of optional providers", func(t *testing.T) {
+		iocContainer = ioc.NewContainerImpl()
+		SetProfiles("prod", "local")
+		_ = AddValueProvider(NewMapValueProvider(map[string]any{"str": "base"}))
+		_ = AddValueProvider(&profileValueProvider{
+			values:   map[string]any{"int": 1},
+			profiles: map[string]map[string]any{"prod": {"int": 2}},
+		}, ProviderName("remote"), ProviderOptional())
+		_ = AddValueProvider(&profileValueProvider{
+			err:      fmt.Errorf("unavailable: %w", os.ErrNotExist),
+			profiles: map[string]map[string]any{"prod": {"str": "skipped"}},
+		}, ProviderName("offline"), ProviderOptional())
+
+		i, _, err := GetValue[int]("int")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, i)
+		str, _, err := GetValue[string]("str")
+		assert.Nil(t, err)
+		assert.Equal(t, "base", str)
+
+		statuses := ValueProviderStatuses()
+		assert.Equal(t, 3, len(statuses))
+		assert.Equal(t, "remote", statuses[1].Name)
+		assert.Nil(t, statuses[1].Err)
+		assert.Equal(t, "offline", statuses[2].Name)
+		assert.EqualError(t, statuses[2].Err, "unavailable: file does not exist")
+	})
+
+	t.Run("get remote values", func(t *testing.T) {
+		var mu sync.Mutex
+		document, contentType, etag, notModified := `{"remote": {"port": 8080}}`, "application/json", `"v1"`, 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if r.Header.Get("If-None-Match") == etag {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Content-Type", contentType)
+			_, _ = w.Write([]byte(document))
+		}))
+		defer server.Close()
+
+		cacheFile := filepath.Join(t.TempDir(), "remote.json")
+		provider := NewHTTPValueProvider(server.URL, HTTPCacheFile(cacheFile), HTTPTimeout(time.Second), HTTPWatchInterval(10*time.Millisecond))
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(provider)
+		port, _, err := GetValue[int]("remote.port")
+		assert.Nil(t, err)
+		assert.Equal(t, 8080, port)
+
+		changed := make(chan ValueChange, 1)
+		OnValueChange("remote.**", func(change ValueChange) {
+			changed <- change
+		})
+		stop := Watch()
+		defer stop()
+		mu.Lock()
+		document, contentType, etag = "remote:\n  port: 9090\n", "application/yaml", `"v2"`
+		mu.Unlock()
+		select {
+		case change := <-changed:
+			assert.Equal(t, ValueChange{Key: "remote.port", Old: float64(8080), New: 9090}, change)
+		case <-time.After(5 * time.Second):
+			t.Fatal("remote change not notified")
+		}
+		mu.Lock()
+		assert.True(t, notModified > 0)
+		mu.Unlock()
+
+		server.Close()
+		assert.Nil(t, Refresh())
+		port, _, _ = GetValue[int]("remote.port")
+		assert.Equal(t, 9090, port)
+		assert.NotNil(t, provider.LastError())
+
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewHTTPValueProvider(server.URL, HTTPCacheFile(cacheFile)))
+		port, _, err = GetValue[int]("remote.port")
+		assert.Nil(t, err)
+		assert.Equal(t, 9090, port)
+	})
+
+	t.Run("watch and cache remote documents by extension", func(t *testing.T) {
+		var mu sync.Mutex
+		document := "remote.port=8080\n"
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			_, _ = w.Write([]byte(document))
+		}))
+		defer server.Close()
+
+		cacheFile := filepath.Join(t.TempDir(), "remote.cache")
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewHTTPValueProvider(server.URL+"/config.properties", HTTPCacheFile(cacheFile), HTTPWatchInterval(time.Hour)))
+		port, _, err := GetValue[int]("remote.port")
+		assert.Nil(t, err)
+		assert.Equal(t, 8080, port)
+
+		// the change published before Watch is notified by the first poll
+		mu.Lock()
+		document = "remote.port=9090\n"
+		mu.Unlock()
+		changed := make(chan ValueChange, 1)
+		OnValueChange("remote.**", func(change ValueChange) {
+			changed <- change
+		})
+		stop := Watch()
+		defer stop()
+		select {
+		case change := <-changed:
+			assert.Equal(t, ValueChange{Key: "remote.port", Old: "8080", New: "9090"}, change)
+		case <-time.After(5 * time.Second):
+			t.Fatal("remote change not notified")
+		}
+
+		server.Close()
+		iocContainer = ioc.NewContainerImpl()
+		_ = AddValueProvider(NewHTTPValueProvider(server.URL+"/config.properties", HTTPCacheFile(cacheFile)))
+		port, _, err = GetValue[int]("remote.port")
+		assert.Nil(t, err)
+		assert.Equal(t, 9090, port)
+	})
+
+	t.Run("limit remote documents", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"remote": {"port": 8080}}`))
+		}))
+		defer server.Close()
+
+		_, err := NewHTTPValueProvider(server.URL, HTTPMaxBodySize(8)).Provide()
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "exceeds 8 bytes")
+
+		values, err := NewHTTPValueProvider(server.URL, HTTPMaxBodySize(26)).Provide()
+		assert.Nil(t, err)
+		assert.NotNil(t, values["remote"])
+	})
+
 	t.Run("get value", func(t *testing.T) {
 		iocContainer = ioc.NewContainerImpl()
 		_ = AddValueProvider(NewFileValueProvider("testdata/config.yaml"))
@@ -0,0 +1,180 @@
+// Package ioctest isolates the global ioc container in tests.
+//
+// Example:
+//
+//	func TestService(t *testing.T) {
+//		c := ioctest.New(t).Values(map[string]any{"app": map[string]any{"name": "test"}})
+//		ioctest.Mock[Client](c, "client", &FakeClient{})
+//
+//		svc, err := ioc.GetObject[Service]("")
+//		...
+//		ioctest.AssertInitCalled[Service](c, "")
+//	}
+//
+// The global container is replaced during the test, so tests using this package must not run in parallel.
+package ioctest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sakuradon99/ioc"
+	"reflect"
+	"testing"
+)
+
+// Container is the isolated container of a test.
+type Container struct {
+	t testing.TB
+}
+
+// New replaces the global container with an isolated copy of the current registrations and value providers,
+// the original container is restored when the test finishes.
+// The test must not call t.Parallel, as the global container is swapped without synchronization.
+func New(t testing.TB) *Container {
+	t.Helper()
+	t.Cleanup(ioc.Isolate())
+	return &Container{t: t}
+}
+
+// Values overrides the values with the map until the test finishes, the map takes priority over all the value providers.
+// The keys are set like ioc.SetValue, eg. "app.name".
+func (c *Container) Values(values map[string]any) *Container {
+	c.t.Helper()
+	restore, err := ioc.WithValues(values)
+	if err != nil {
+		c.t.Fatalf("set values failed, err=%v", err)
+	}
+	c.t.Cleanup(restore)
+	return c
+}
+
+// Mock overrides the implementation of the interface I with the name by the mock, or registers the mock if there is none.
+// The mock must be a pointer to a struct. The mock replaces the implementation like ioc.ReplaceTarget,
+// so the objects injecting the implementation by its struct type no longer find it.
+func Mock[I any](c *Container, name string, mock I) {
+	c.t.Helper()
+	rtp := reflect.TypeOf(new(I)).Elem()
+	if rtp.Kind() != reflect.Interface {
+		c.t.Fatalf("mock type [%s] is not an interface, use MockObject instead", rtp)
+	}
+	mv := reflect.ValueOf(mock)
+	if !mv.IsValid() || mv.Kind() != reflect.Ptr || mv.Elem().Kind() != reflect.Struct {
+		c.t.Fatalf("mock of [%s] must be a pointer to a struct", rtp)
+	}
+
+	ft := reflect.FuncOf(nil, []reflect.Type{mv.Type()}, false)
+	constructor := reflect.MakeFunc(ft, func([]reflect.Value) []reflect.Value {
+		return []reflect.Value{mv}
+	})
+	err := provide(constructor.Interface(), ioc.Name(name), ioc.ReplaceTarget[I]())
+	if errors.Is(err, ioc.ErrMissingReplaceTarget) {
+		// there is no implementation registered yet
+		err = provide(constructor.Interface(), ioc.Name(name))
+	}
+	if err != nil {
+		c.t.Fatalf("mock [%s] failed, err=%v", rtp, err)
+	}
+}
+
+// MockObject overrides the object of the struct T with the name by the mock.
+// The mock takes the place of the object in its module.
+func MockObject[T any](c *Container, name string, mock *T) {
+	c.t.Helper()
+	constructor := func() *T { return mock }
+	err := provide(constructor, ioc.Name(name), ioc.Replace())
+	if errors.Is(err, ioc.ErrMissingReplaceTarget) {
+		// there is no such object to replace
+		err = provide(constructor, ioc.Name(name))
+	}
+	if err != nil {
+		c.t.Fatalf("mock object [%s] failed, err=%v", reflect.TypeOf(mock).Elem(), err)
+	}
+}
+
+// AssertBuilt asserts that the object of T with the name has been built.
+// T can be a struct or an interface.
+func AssertBuilt[T any](c *Container, name string) bool {
+	c.t.Helper()
+	info, ok := findObject[T](c, name)
+	if !ok {
+		return false
+	}
+	if !info.Built {
+		c.t.Errorf("object <%s@%s> was not built", info.Type, info.Name)
+		return false
+	}
+	return true
+}
+
+// AssertNotBuilt asserts that the object of T with the name has not been built.
+// T can be a struct or an interface.
+func AssertNotBuilt[T any](c *Container, name string) bool {
+	c.t.Helper()
+	info, ok := findObject[T](c, name)
+	if !ok {
+		return false
+	}
+	if info.Built {
+		c.t.Errorf("object <%s@%s> was built", info.Type, info.Name)
+		return false
+	}
+	return true
+}
+
+// AssertInitCalled asserts that the Init method of the object of T with the name has been called.
+// T can be a struct or an interface.
+func AssertInitCalled[T any](c *Container, name string) bool {
+	c.t.Helper()
+	info, ok := findObject[T](c, name)
+	if !ok {
+		return false
+	}
+	if !info.InitCalled {
+		c.t.Errorf("Init of object <%s@%s> was not called", info.Type, info.Name)
+		return false
+	}
+	return true
+}
+
+func findObject[T any](c *Container, name string) (ioc.ObjectInfo, bool) {
+	c.t.Helper()
+	rtp := reflect.TypeOf(new(T)).Elem()
+
+	infos, err := ioc.ListObjects()
+	if err != nil {
+		c.t.Errorf("list objects failed, err=%v", err)
+		return ioc.ObjectInfo{}, false
+	}
+
+	for _, info := range infos {
+		if info.Name != name {
+			continue
+		}
+		if info.RType == rtp {
+			return info, true
+		}
+		if rtp.Kind() == reflect.Interface &&
+			(info.RType.Implements(rtp) || reflect.PointerTo(info.RType).Implements(rtp)) {
+			return info, true
+		}
+	}
+
+	c.t.Errorf("object <%s@%s> not found", rtp, name)
+	return ioc.ObjectInfo{}, false
+}
+
+// provide calls ioc.Provide and returns its panic as an error.
+func provide(constructor any, opts ...ioc.RegisterOption) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+				return
+			}
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	ioc.Provide(constructor, opts...)
+	return nil
+}
@@ -0,0 +1,138 @@
+package ioctest
+
+import (
+	"fmt"
+	"github.com/sakuradon99/ioc"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Greeter interface {
+	Greet() string
+}
+
+type greeterImpl struct {
+	name string `value:"name"`
+}
+
+func (g *greeterImpl) Greet() string {
+	return "hello " + g.name
+}
+
+type mockGreeter struct {
+}
+
+func (m *mockGreeter) Greet() string {
+	return "mock"
+}
+
+type otherGreeter struct {
+}
+
+func (g *otherGreeter) Greet() string {
+	return "other"
+}
+
+// fatalRecorder records the fatal failure instead of stopping the test
+type fatalRecorder struct {
+	testing.TB
+	fatal string
+}
+
+func (r *fatalRecorder) Helper() {
+}
+
+func (r *fatalRecorder) Fatalf(format string, args ...any) {
+	r.fatal = fmt.Sprintf(format, args...)
+}
+
+type Service struct {
+	greeter Greeter `inject:""`
+	name    string  `value:"name"`
+	inited  bool
+}
+
+func (s *Service) Init() error {
+	s.inited = true
+	return nil
+}
+
+type Unused struct {
+}
+
+var _ = ioc.Register[greeterImpl]()
+var _ = ioc.Register[Service]()
+var _ = ioc.Register[Unused](ioc.Optional())
+
+func Test_IOCTest(t *testing.T) {
+	t.Run("isolate with values", func(t *testing.T) {
+		c := New(t).Values(map[string]any{"name": "world"})
+
+		s, err := ioc.GetObject[Service]("")
+		assert.Nil(t, err)
+		assert.Equal(t, "hello world", s.greeter.Greet())
+		assert.True(t, s.inited)
+
+		AssertBuilt[Service](c, "")
+		AssertBuilt[Greeter](c, "")
+		AssertInitCalled[Service](c, "")
+		AssertNotBuilt[Unused](c, "")
+	})
+
+	t.Run("isolate with mocks", func(t *testing.T) {
+		c := New(t).Values(map[string]any{"name": "mocked"})
+		Mock[Greeter](c, "", &mockGreeter{})
+		MockObject[Unused](c, "", &Unused{})
+
+		s, err := ioc.GetObject[Service]("")
+		assert.Nil(t, err)
+		assert.Equal(t, "mock", s.greeter.Greet())
+		assert.Equal(t, "mocked", s.name)
+	})
+
+	t.Run("values take priority over all providers", func(t *testing.T) {
+		New(t).Values(map[string]any{"name": "test"})
+		_ = ioc.AddValueProvider(ioc.NewMapValueProvider(map[string]any{"name": "prioritised"}), ioc.ProviderPriority(10))
+
+		name, _, err := ioc.GetValue[string]("name")
+		assert.Nil(t, err)
+		assert.Equal(t, "test", name)
+	})
+
+	t.Run("fail on ambiguous mocks", func(t *testing.T) {
+		New(t)
+		ioc.Register[otherGreeter]()
+		recorder := &fatalRecorder{TB: t}
+		Mock[Greeter](&Container{t: recorder}, "", &mockGreeter{})
+
+		assert.True(t, strings.HasPrefix(recorder.fatal, "mock [ioctest.Greeter] failed"))
+		infos, err := ioc.ListObjects()
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(infos))
+	})
+
+	t.Run("restore after isolation", func(t *testing.T) {
+		t.Run("isolated", func(t *testing.T) {
+			c := New(t)
+			Mock[Greeter](c, "", &mockGreeter{})
+			infos, err := ioc.ListObjects()
+			assert.Nil(t, err)
+			assert.Equal(t, 3, len(infos))
+			for _, info := range infos {
+				assert.False(t, info.Built)
+			}
+		})
+
+		// the cleanup of the isolated test restores the original container without the mock
+		infos, err := ioc.ListObjects()
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(infos))
+		for _, info := range infos {
+			assert.NotEqual(t, reflect.TypeOf(mockGreeter{}), info.RType)
+			assert.False(t, info.Built)
+		}
+		assert.Equal(t, 0, len(ioc.ListOverrides()))
+	})
+}
@@ -4,6 +4,15 @@ import ioc "github.com/sakuradon99/ioc/internal"
 
 type RegisterOption = ioc.RegisterOption
 
+type ModuleOption = ioc.ModuleOption
+
+type BindOption = ioc.BindOption
+
+type ProviderOption = ioc.ProviderOption
+
+// Out marks a struct returned by a constructor as a result struct, see Provide.
+type Out = ioc.Out
+
 // Name sets the name expression for the registered object.
 // The name expression can be used to inject the object by name, without this option, the name will be empty "".
 // Example: `Name("myService")`, in the field, you can use `inject:"myService"` to inject this object.
@@ -38,6 +47,40 @@ func Optional() ioc.RegisterOption {
 	}
 }
 
+// Private marks the registered object as private to its module.
+// A private object can only be injected into the objects of the same module or its sub modules,
+// and can not be retrieved outside the module.
+func Private() ioc.RegisterOption {
+	return func(o *ioc.RegisterOptions) {
+		o.Private = true
+	}
+}
+
+// ErrMissingReplaceTarget is matched by errors.Is when Replace or ReplaceTarget find no object to replace.
+var ErrMissingReplaceTarget = ioc.ErrMissingReplaceTarget
+
+// Replace replaces the registered object with the same type and name instead of failing as a duplicate.
+// It fails if there is no such object, the replaced objects can be listed by ListOverrides.
+// The replacement should be registered before any object is retrieved.
+// Example: `Provide(newFakeClient, Name("client"), Replace())` in a test or an environment-specific package.
+func Replace() ioc.RegisterOption {
+	return func(o *ioc.RegisterOptions) {
+		o.Replace = true
+	}
+}
+
+// ReplaceTarget replaces the implementation of the interface T with the same name,
+// the replacement can be of another type implementing T.
+// It fails if there is no such implementation or there are more than one.
+// Example: `Register[MockClient](Name("client"), ReplaceTarget[Client]())` replaces the implementation of Client.
+func ReplaceTarget[T any]() ioc.RegisterOption {
+	rtp := getRefType[T]()
+	return func(o *ioc.RegisterOptions) {
+		o.Replace = true
+		o.ReplaceType = rtp
+	}
+}
+
 // Constructor sets the constructor function for the registered object.
 // The constructor function will be called to create the object when it is requested.
 // The signature of the constructor function should be:
@@ -46,8 +89,11 @@ func Optional() ioc.RegisterOption {
 // - `func(Dependency1, Dependency2...) Object`
 // - `func(Dependency1, Dependency2...) (*Object, error)`
 // Example: `Constructor(func() *MyService { return &MyService{} })`
-// The type of dependencies can only be pointer, interface or struct.
+// The type of dependencies can only be pointer, interface, slice, map or struct.
 // If the dependency is pointer or interface, it will be injected automatically by the IOC container.
+// If the dependency is a slice (`[]MyInterface`, `[]*MyStruct`) or a map (`map[string]MyInterface`),
+// all matching objects will be injected, variadic params like `...MyInterface` are supported too.
+// Example: `Constructor(func(handlers ...Handler) *Router { return &Router{handlers: handlers} })`
 // If the dependency is a struct, the container will create a new instance of the struct and inject its fields.
 func Constructor(constructor any) ioc.RegisterOption {
 	return func(o *ioc.RegisterOptions) {
@@ -64,3 +110,69 @@ func Conditional(expr string) ioc.RegisterOption {
 		o.ConditionExpr = expr
 	}
 }
+
+// Profile registers the object only if any of the profiles is active,
+// a profile like "!prod" is active if "prod" is not active.
+// It is joined with the condition set by Conditional by `&&`.
+// Example: `Profile("dev", "staging")`
+func Profile(profiles ...string) ioc.RegisterOption {
+	return func(o *ioc.RegisterOptions) {
+		o.Profiles = append(o.Profiles, profiles...)
+	}
+}
+
+// Prefix sets the name prefix for the objects of the module.
+// The prefix is added to the names and aliases of the objects, unnamed objects keep the empty name.
+// Example: `Module("billing", Prefix("billing."), Register[Service](Name("service")))`,
+// the name of the object will be "billing.service".
+// Place Prefix before the registrations of the module, the names they share with other objects are checked
+// once the prefix is applied, the duplicate names of the other registrations are reported immediately.
+func Prefix(prefix string) ioc.ModuleOption {
+	if prefix != "" {
+		iocContainer.OpenPrefix()
+	}
+	return func(o *ioc.ModuleOptions) {
+		o.Prefix = prefix
+	}
+}
+
+// ModuleConditional sets a condition expression shared by the objects of the module.
+// The condition is joined with the conditions of the objects by `&&`.
+// Example: `ModuleConditional("#billing.enabled == true")`
+func ModuleConditional(expr string) ioc.ModuleOption {
+	return func(o *ioc.ModuleOptions) {
+		o.ConditionExpr = expr
+	}
+}
+
+// BindStrict fails the binding if any key under the prefix is not bound to a field,
+// the unknown keys are reported in the error. The config tag option is `strict`, eg. `config:"app.db;strict"`.
+func BindStrict() ioc.BindOption {
+	return func(o *ioc.BindOptions) {
+		o.Strict = true
+	}
+}
+
+// ProviderName names the value provider in the explanations of the values and the statuses of the providers,
+// the default name is the String of the provider, eg. "file config.yaml".
+func ProviderName(name string) ioc.ProviderOption {
+	return func(o *ioc.ProviderOptions) {
+		o.Name = name
+	}
+}
+
+// ProviderOptional skips the value provider if its source is missing, eg. a missing local override file,
+// the error is reported by ValueProviderStatuses. The other errors still fail, eg. a malformed file. The profile-specific variants of a skipped provider are skipped as well.
+func ProviderOptional() ioc.ProviderOption {
+	return func(o *ioc.ProviderOptions) {
+		o.Optional = true
+	}
+}
+
+// ProviderPriority sets the priority of the value provider, the default priority is 0.
+// The providers with higher priorities override the values of the others regardless of the order they are added.
+func ProviderPriority(priority int) ioc.ProviderOption {
+	return func(o *ioc.ProviderOptions) {
+		o.Priority = priority
+	}
+}
@@ -1,16 +1,58 @@
 package ioc
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
+	"flag"
+	"fmt"
 	ioc "github.com/sakuradon99/ioc/internal"
-	"gopkg.in/yaml.v3"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type ValueProvider = ioc.ValueProvider
 
+type ValueChange = ioc.ValueChange
+
+type ValueInfo = ioc.ValueInfo
+
+type ValueOrigin = ioc.ValueOrigin
+
+type ProviderStatus = ioc.ProviderStatus
+
+type MergeMode = ioc.MergeMode
+
+type ListStrategy = ioc.ListStrategy
+
+const (
+	// MergeOverride returns the value of the provider with the highest priority providing the key.
+	MergeOverride = ioc.MergeOverride
+	// MergeDeep merges the maps of the key across all the providers.
+	MergeDeep = ioc.MergeDeep
+)
+
+const (
+	// ListReplace replaces the list by the list of the provider with the higher priority.
+	ListReplace = ioc.ListReplace
+	// ListAppend appends the list of the provider with the higher priority to the list.
+	ListAppend = ioc.ListAppend
+	// ListMergeByIndex merges the elements of the lists by their indexes.
+	ListMergeByIndex = ioc.ListMergeByIndex
+)
+
+// DefaultWatchInterval is the default interval of polling the files of the watched value providers.
+const DefaultWatchInterval = 2 * time.Second
+
 type MapValueProvider struct {
 	valueMap map[string]any
 }
@@ -23,32 +65,834 @@ func (m *MapValueProvider) Provide() (map[string]any, error) {
 	return m.valueMap, nil
 }
 
+func (m *MapValueProvider) String() string {
+	return "map"
+}
+
+// FileValueProvider provides values from a file, the format is detected by the extension of the file.
+// The builtin formats are .json, .yaml, .yml, .toml, .properties, .ini and .env,
+// other formats can be added by RegisterValueDecoder.
+// The profile-specific file, eg. `config-prod.yaml` for `config.yaml`, is layered over the file if the profile is active.
+// The file is polled for changes if watched, see Watch.
 type FileValueProvider struct {
-	file string
+	file          string
+	optional      bool
+	watchInterval time.Duration
+	mu            sync.Mutex
+	// lines are the lines of the dotted keys of the last provided YAML file
+	lines map[string]int
 }
 
-func NewFileValueProvider(file string) *FileValueProvider {
-	return &FileValueProvider{file: file}
+type FileValueOption func(p *FileValueProvider)
+
+// FileWatchInterval sets the interval of polling the file for changes, the default interval is DefaultWatchInterval.
+func FileWatchInterval(interval time.Duration) FileValueOption {
+	return func(p *FileValueProvider) {
+		p.watchInterval = interval
+	}
+}
+
+func NewFileValueProvider(file string, opts ...FileValueOption) *FileValueProvider {
+	p := &FileValueProvider{file: file, watchInterval: DefaultWatchInterval}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 func (f *FileValueProvider) Provide() (map[string]any, error) {
 	content, err := os.ReadFile(f.file)
 	if err != nil {
+		if f.optional && os.IsNotExist(err) {
+			return nil, nil
+		}
 		return nil, err
 	}
 
-	valueMap := make(map[string]any)
-	if strings.HasSuffix(f.file, ".json") {
-		err = json.Unmarshal(content, &valueMap)
-	} else if strings.HasSuffix(f.file, ".yaml") || strings.HasSuffix(f.file, ".yml") {
-		err = yaml.Unmarshal(content, &valueMap)
-	} else {
-		return nil, errors.New("unsupported file format, only .json, .yaml, and .yml are supported")
+	decoder, err := getValueDecoder(f.file)
+	if err != nil {
+		return nil, err
+	}
+
+	valueMap, err := decoder(content)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lines = nil
+	if ext := strings.ToLower(filepath.Ext(f.file)); ext == ".yaml" || ext == ".yml" {
+		f.lines = yamlKeyLines(content)
+	}
+	return valueMap, nil
+}
+
+func (f *FileValueProvider) String() string {
+	return "file " + f.file
+}
+
+// Locate returns the file and the line of the keys, or of their nearest parent key, the lines are known for YAML files.
+func (f *FileValueProvider) Locate(keys []string) (string, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := len(keys); i > 0; i-- {
+		if line, ok := f.lines[strings.Join(keys[:i], ".")]; ok {
+			return f.file, line
+		}
+	}
+	return f.file, 0
+}
+
+// ProfileProvider returns the provider of the profile-specific file, the missing file is skipped.
+func (f *FileValueProvider) ProfileProvider(profile string) ValueProvider {
+	return &FileValueProvider{file: f.profileFile(profile), optional: true}
+}
+
+func (f *FileValueProvider) profileFile(profile string) string {
+	ext := filepath.Ext(f.file)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(f.file, ext), profile, ext)
+}
+
+// RelaxedKeys reports that the keys of .env files are matched relaxedly like the keys of EnvValueProvider,
+// eg. `APP_DB_MAX_CONNS` provides `app.db.max_conns`.
+func (f *FileValueProvider) RelaxedKeys() bool {
+	return strings.ToLower(filepath.Ext(f.file)) == ".env"
+}
+
+// Watch polls the modification time and size of the file and the files of its profiles.
+func (f *FileValueProvider) Watch(notify func()) (stop func()) {
+	dir := filepath.Dir(f.file)
+	base := filepath.Base(f.file)
+	ext := filepath.Ext(base)
+	profilePattern := fmt.Sprintf("%s-*%s", strings.TrimSuffix(base, ext), ext)
+	fingerprint := func() string {
+		files, _ := filepath.Glob(filepath.Join(dir, profilePattern))
+		return fileFingerprint(append([]string{f.file}, files...)...)
 	}
+	return pollFingerprint(f.watchInterval, fingerprint(), fingerprint, notify)
+}
+
+// DirValueProvider provides values from a directory of one file per key,
+// like the ConfigMaps and Secrets mounted by Kubernetes.
+// The file names are split by "." into nested keys and the subdirectories are nested as well,
+// eg. `/etc/config/db.password` and `/etc/config/db/password` both provide `db.password`,
+// Provide fails if several files provide the same key or a file provides a parent key of another file.
+// The trailing newlines of the files are trimmed.
+// The Kubernetes artefacts starting with "..", like `..data`, are always ignored, the symlinks into them are followed,
+// and a symlink looping back to a parent directory fails Provide.
+// The directory is polled for changes if watched, see Watch.
+type DirValueProvider struct {
+	dir            string
+	parseFiles     bool
+	ignoreDotFiles bool
+	watchInterval  time.Duration
+	mu             sync.Mutex
+	// files are the files of the dotted keys of the last provided values
+	files map[string]string
+}
+
+type DirValueOption func(p *DirValueProvider)
 
+// DirParseFiles parses the files whose extensions have decoders, see RegisterValueDecoder,
+// the extension is trimmed from the key, eg. `db.yaml` provides the decoded map as `db`.
+func DirParseFiles() DirValueOption {
+	return func(p *DirValueProvider) {
+		p.parseFiles = true
+	}
+}
+
+// DirIgnoreDotFiles ignores the files and subdirectories starting with ".".
+func DirIgnoreDotFiles() DirValueOption {
+	return func(p *DirValueProvider) {
+		p.ignoreDotFiles = true
+	}
+}
+
+// DirWatchInterval sets the interval of polling the directory for changes, the default interval is DefaultWatchInterval.
+func DirWatchInterval(interval time.Duration) DirValueOption {
+	return func(p *DirValueProvider) {
+		p.watchInterval = interval
+	}
+}
+
+func NewDirValueProvider(dir string, opts ...DirValueOption) *DirValueProvider {
+	p := &DirValueProvider{dir: dir, watchInterval: DefaultWatchInterval}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (d *DirValueProvider) Provide() (map[string]any, error) {
+	valueMap := make(map[string]any)
+	files := make(map[string]string)
+	realDir, err := filepath.EvalSymlinks(d.dir)
 	if err != nil {
 		return nil, err
 	}
+	err = d.provideDir(d.dir, nil, valueMap, files, map[string]bool{realDir: true})
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.files = files
+	return valueMap, nil
+}
+
+func (d *DirValueProvider) String() string {
+	return "dir " + d.dir
+}
+
+// Locate returns the file of the keys, or of their nearest parent key, eg. the parsed file `db.yaml` of `db.host`.
+func (d *DirValueProvider) Locate(keys []string) (string, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i := len(keys); i > 0; i-- {
+		if file, ok := d.files[strings.Join(keys[:i], ".")]; ok {
+			return file, 0
+		}
+	}
+	return d.dir, 0
+}
+
+// Watch polls the files of the directory, the swaps of the Kubernetes `..data` symlink are detected as well.
+func (d *DirValueProvider) Watch(notify func()) (stop func()) {
+	fingerprint := func() string {
+		var paths []string
+		_ = filepath.WalkDir(d.dir, func(path string, _ os.DirEntry, err error) error {
+			if err == nil {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		return fileFingerprint(paths...)
+	}
+	return pollFingerprint(d.watchInterval, fingerprint(), fingerprint, notify)
+}
+
+// provideDir provides the values of the files of the dir, ancestors are the real paths of the dir and its parents,
+// so that a symlink to any of them is reported instead of followed forever.
+func (d *DirValueProvider) provideDir(dir string, parentKeys []string, valueMap map[string]any, files map[string]string, ancestors map[string]bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "..") || (d.ignoreDotFiles && strings.HasPrefix(name, ".")) {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		// follow symlinks, the keys of Kubernetes mounts are symlinks into `..data`
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			realPath, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				return err
+			}
+			if ancestors[realPath] {
+				return fmt.Errorf("directory %s is a symlink loop to %s", path, realPath)
+			}
+			keys := append(append([]string(nil), parentKeys...), splitFileKey(name)...)
+			ancestors[realPath] = true
+			err = d.provideDir(path, keys, valueMap, files, ancestors)
+			delete(ancestors, realPath)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var value any = strings.TrimRight(string(content), "\r\n")
+		if d.parseFiles {
+			if decoder, err := getValueDecoder(name); err == nil {
+				value, err = decoder(content)
+				if err != nil {
+					return fmt.Errorf("decode file %s failed, err=%w", path, err)
+				}
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+		}
+
+		keys := append(append([]string(nil), parentKeys...), splitFileKey(name)...)
+		if len(keys) == 0 {
+			continue
+		}
+		if hasNestedValue(valueMap, keys) {
+			return fmt.Errorf("keys of file %s conflict with file %s", path, conflictFile(files, strings.Join(keys, ".")))
+		}
+		setNestedValue(valueMap, keys, value)
+		files[strings.Join(keys, ".")] = path
+	}
+
+	return nil
+}
+
+// hasNestedValue reports whether setting the keys would overwrite a value, eg. `db.host` is set by both the file `db.host` and `db/host`.
+func hasNestedValue(m map[string]any, keys []string) bool {
+	var value any = m
+	for _, key := range keys {
+		next, ok := value.(map[string]any)
+		if !ok {
+			return true
+		}
+		value, ok = next[key]
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// conflictFile returns the first file of the keys overlapping the key, eg. the file of `db` for `db.host`.
+func conflictFile(files map[string]string, key string) string {
+	var conflicts []string
+	for k, file := range files {
+		if k == key || strings.HasPrefix(key, k+".") || strings.HasPrefix(k, key+".") {
+			conflicts = append(conflicts, file)
+		}
+	}
+	sort.Strings(conflicts)
+	if len(conflicts) == 0 {
+		return ""
+	}
+	return conflicts[0]
+}
+
+// splitFileKey splits the file name by "." into keys, the empty keys are dropped, eg. ".hidden" is "hidden".
+func splitFileKey(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return r == '.'
+	})
+}
+
+// EnvValueProvider provides values from environment variables.
+// The variable names are lowercased and split by the separator into nested keys,
+// eg. `APP_DB_HOST=localhost` provides `app.db.host`.
+// The keys are matched relaxedly in the values of the variables only, ignoring case, "-" and "_",
+// and a key containing "-" or "_" also matches the nested keys split by them,
+// so both `APP_DB_MAX_CONNS` and `APP_DB_MAXCONNS` override `app.db.max_conns`.
+// Lists can be set by indexed variables like `APP_SERVERS_0` and `APP_SERVERS_1`, the indexes must be consecutive from 0,
+// or by a comma-separated variable like `APP_SERVERS=a,b`.
+// Without EnvPrefix, all the variables are provided, so variables like `HOME` or `USER` override the keys `home` or `user`.
+// Add it after the file providers to override the values of the files.
+type EnvValueProvider struct {
+	prefix    string
+	separator string
+}
+
+type EnvValueOption func(p *EnvValueProvider)
+
+// EnvPrefix only provides the variables starting with the prefix and the separator, the prefix is trimmed from keys.
+// Example: with `EnvPrefix("MYAPP")`, `MYAPP_DB_HOST` provides `db.host`.
+func EnvPrefix(prefix string) EnvValueOption {
+	return func(p *EnvValueProvider) {
+		p.prefix = prefix
+	}
+}
+
+// EnvSeparator sets the separator of nested keys, the default separator is "_".
+func EnvSeparator(separator string) EnvValueOption {
+	return func(p *EnvValueProvider) {
+		p.separator = separator
+	}
+}
+
+func NewEnvValueProvider(opts ...EnvValueOption) *EnvValueProvider {
+	p := &EnvValueProvider{separator: "_"}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (e *EnvValueProvider) String() string {
+	if e.prefix == "" {
+		return "env"
+	}
+	return "env " + e.prefix
+}
+
+// RelaxedKeys reports that the keys are matched relaxedly, as they are normalized from the variable names.
+func (e *EnvValueProvider) RelaxedKeys() bool {
+	return true
+}
+
+func (e *EnvValueProvider) Provide() (map[string]any, error) {
+	if e.separator == "" {
+		return nil, errors.New("env separator cannot be empty")
+	}
+
+	names := make([]string, 0)
+	nameToValue := make(map[string]string)
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if e.prefix != "" {
+			if !strings.HasPrefix(name, e.prefix+e.separator) {
+				continue
+			}
+			name = strings.TrimPrefix(name, e.prefix+e.separator)
+		}
+		if name == "" {
+			continue
+		}
+		names = append(names, name)
+		nameToValue[name] = value
+	}
+	// parent keys are set before nested keys, so that nested keys win over conflicting parent values
+	sort.Strings(names)
+
+	valueMap := make(map[string]any)
+	for _, name := range names {
+		setNestedValue(valueMap, envNameToKeys(name, e.separator), nameToValue[name])
+	}
+
+	for k, v := range valueMap {
+		valueMap[k] = indexedMapsToSlices(v)
+	}
+	return valueMap, nil
+}
+
+// envNameToKeys splits the variable name into the canonical keys, see ioc.CanonicalKey.
+func envNameToKeys(name string, separator string) []string {
+	keys := strings.Split(name, separator)
+	for i, key := range keys {
+		keys[i] = ioc.CanonicalKey(key)
+	}
+	return keys
+}
+
+func setNestedValue(m map[string]any, keys []string, value any) {
+	key := keys[0]
+	if len(keys) == 1 {
+		m[key] = value
+		return
+	}
+
+	next, ok := m[key].(map[string]any)
+	if !ok {
+		next = make(map[string]any)
+		m[key] = next
+	}
+	setNestedValue(next, keys[1:], value)
+}
+
+// indexedMapsToSlices converts the maps whose keys are the consecutive indexes from 0 into slices,
+// the maps of sparse indexes like "1000000000" are kept as maps.
+func indexedMapsToSlices(value any) any {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+
+	indexed := len(m) > 0
+	for k, v := range m {
+		m[k] = indexedMapsToSlices(v)
+		// the keys are distinct, so the indexes below the length are consecutive
+		index, err := strconv.Atoi(k)
+		if err != nil || index < 0 || index >= len(m) || strconv.Itoa(index) != k {
+			indexed = false
+		}
+	}
+	if !indexed {
+		return m
+	}
+
+	slice := make([]any, len(m))
+	for k, v := range m {
+		index, _ := strconv.Atoi(k)
+		slice[index] = v
+	}
+	return slice
+}
+
+// FlagValueProvider provides values from command-line arguments or the flags of a flag.FlagSet.
+// The flag names are split by "." into nested keys, eg. `--app.port=8080` provides `app.port`.
+// Add it after the file and env providers to override their values.
+type FlagValueProvider struct {
+	args    []string
+	flagSet *flag.FlagSet
+}
+
+// NewFlagValueProvider parses the arguments like `--app.port=8080`, `--app.port 8080` or `--app.debug`,
+// a flag without a value is true, a repeated flag provides a list.
+// The parsing stops at the terminator "--", and the arguments not starting with "--" are ignored.
+// Example: `NewFlagValueProvider(os.Args[1:])`
+func NewFlagValueProvider(args []string) *FlagValueProvider {
+	return &FlagValueProvider{args: args}
+}
+
+// NewFlagSetValueProvider provides the flags set in the flag set, the flag set should be parsed before retrieving values.
+// If the flag value implements flag.Getter, the typed value is provided.
+// Example: `flag.String("app.name", "", "app name")` with `NewFlagSetValueProvider(flag.CommandLine)`
+func NewFlagSetValueProvider(flagSet *flag.FlagSet) *FlagValueProvider {
+	return &FlagValueProvider{flagSet: flagSet}
+}
+
+func (f *FlagValueProvider) String() string {
+	return "flags"
+}
+
+func (f *FlagValueProvider) Provide() (map[string]any, error) {
+	valueMap := make(map[string]any)
+
+	if f.flagSet != nil {
+		if !f.flagSet.Parsed() {
+			return nil, fmt.Errorf("flag set %s is not parsed", f.flagSet.Name())
+		}
+		f.flagSet.Visit(func(fl *flag.Flag) {
+			var value any = fl.Value.String()
+			if getter, ok := fl.Value.(flag.Getter); ok {
+				value = getter.Get()
+			}
+			setNestedValue(valueMap, strings.Split(fl.Name, "."), value)
+		})
+		return valueMap, nil
+	}
+
+	nameToValues := make(map[string][]any)
+	var names []string
+	for i := 0; i < len(f.args); i++ {
+		arg := f.args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "--") || len(arg) == 2 {
+			continue
+		}
+
+		name, value, ok := strings.Cut(arg[2:], "=")
+		if name == "" {
+			return nil, fmt.Errorf("bad flag syntax: %s", arg)
+		}
+		if !ok {
+			if i+1 < len(f.args) && !strings.HasPrefix(f.args[i+1], "--") {
+				value = f.args[i+1]
+				i++
+			} else {
+				value = "true"
+			}
+		}
+		if _, exist := nameToValues[name]; !exist {
+			names = append(names, name)
+		}
+		nameToValues[name] = append(nameToValues[name], value)
+	}
+
+	for _, name := range names {
+		values := nameToValues[name]
+		if len(values) == 1 {
+			setNestedValue(valueMap, strings.Split(name, "."), values[0])
+			continue
+		}
+		setNestedValue(valueMap, strings.Split(name, "."), values)
+	}
 
 	return valueMap, nil
 }
+
+// DefaultHTTPTimeout is the default timeout of the requests of HTTPValueProvider.
+const DefaultHTTPTimeout = 10 * time.Second
+
+// DefaultHTTPMaxBodySize is the default maximum size of the documents fetched by HTTPValueProvider.
+const DefaultHTTPMaxBodySize = 10 << 20
+
+// HTTPValueProvider provides values from a JSON or YAML document fetched from an HTTP endpoint, eg. a key/value service.
+// The format is detected by the Content-Type of the response or the extension of the URL path, JSON by default.
+// When watched, the endpoint is polled with If-None-Match, so an unchanged document is not downloaded again.
+// The last fetched values are kept while the endpoint is unavailable, and are saved into the cache file if set,
+// so they are loaded from the cache file if the endpoint is unavailable at startup.
+type HTTPValueProvider struct {
+	url           string
+	client        *http.Client
+	header        http.Header
+	cacheFile     string
+	watchInterval time.Duration
+	maxBodySize   int64
+	mu            sync.Mutex
+	etag          string
+	values        map[string]any
+	// version is the hash of the fetched document, compared to detect the changes
+	version string
+	err     error
+}
+
+type HTTPValueOption func(p *HTTPValueProvider)
+
+// HTTPTimeout sets the timeout of the requests, the default timeout is DefaultHTTPTimeout.
+func HTTPTimeout(timeout time.Duration) HTTPValueOption {
+	return func(p *HTTPValueProvider) {
+		p.client.Timeout = timeout
+	}
+}
+
+// HTTPHeader adds a header to the requests, eg. `HTTPHeader("Authorization", "Bearer "+token)`.
+func HTTPHeader(key string, value string) HTTPValueOption {
+	return func(p *HTTPValueProvider) {
+		p.header.Add(key, value)
+	}
+}
+
+// HTTPCacheFile sets the file caching the last fetched document, it is loaded if the endpoint is unavailable at startup.
+func HTTPCacheFile(file string) HTTPValueOption {
+	return func(p *HTTPValueProvider) {
+		p.cacheFile = file
+	}
+}
+
+// HTTPMaxBodySize sets the maximum size of the documents in bytes, the larger documents fail,
+// the default size is DefaultHTTPMaxBodySize.
+func HTTPMaxBodySize(size int64) HTTPValueOption {
+	return func(p *HTTPValueProvider) {
+		p.maxBodySize = size
+	}
+}
+
+// HTTPWatchInterval sets the interval of polling the endpoint for changes, the default interval is DefaultWatchInterval.
+func HTTPWatchInterval(interval time.Duration) HTTPValueOption {
+	return func(p *HTTPValueProvider) {
+		p.watchInterval = interval
+	}
+}
+
+func NewHTTPValueProvider(endpoint string, opts ...HTTPValueOption) *HTTPValueProvider {
+	p := &HTTPValueProvider{
+		url:           endpoint,
+		client:        &http.Client{Timeout: DefaultHTTPTimeout},
+		header:        make(http.Header),
+		watchInterval: DefaultWatchInterval,
+		maxBodySize:   DefaultHTTPMaxBodySize,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// httpCache is the content of the cache file, the content type selects the decoder of the document like the response.
+type httpCache struct {
+	ContentType string `json:"content_type"`
+	Document    string `json:"document"`
+}
+
+func (h *HTTPValueProvider) Provide() (map[string]any, error) {
+	err := h.fetch()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.values != nil {
+		// the last known good values are kept while the endpoint is unavailable
+		return h.values, nil
+	}
+	if err == nil || h.cacheFile == "" {
+		return h.values, err
+	}
+
+	content, cacheErr := os.ReadFile(h.cacheFile)
+	if cacheErr != nil {
+		return nil, err
+	}
+	values, version, cacheErr := h.decodeCache(content)
+	if cacheErr != nil {
+		return nil, fmt.Errorf("decode cache file %s failed, err=%w", h.cacheFile, cacheErr)
+	}
+	h.values = values
+	h.version = version
+	return values, nil
+}
+
+// decodeCache returns the values and the version of the cached document.
+func (h *HTTPValueProvider) decodeCache(content []byte) (map[string]any, string, error) {
+	var cache httpCache
+	err := json.Unmarshal(content, &cache)
+	if err != nil {
+		return nil, "", err
+	}
+	decoder, err := h.decoder(cache.ContentType)
+	if err != nil {
+		return nil, "", err
+	}
+	values, err := decoder([]byte(cache.Document))
+	if err != nil {
+		return nil, "", err
+	}
+	return values, contentVersion([]byte(cache.Document)), nil
+}
+
+func (h *HTTPValueProvider) String() string {
+	return "http " + h.url
+}
+
+// LastError returns the error of the last request, nil if the last request succeeded.
+func (h *HTTPValueProvider) LastError() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.err
+}
+
+// Watch polls the endpoint with If-None-Match, the failed requests keep the last known good values.
+// The polling starts from the last provided document, so a change published since is notified by the first poll.
+func (h *HTTPValueProvider) Watch(notify func()) (stop func()) {
+	h.mu.Lock()
+	version := h.version
+	h.mu.Unlock()
+
+	return pollFingerprint(h.watchInterval, version, func() string {
+		_ = h.fetch()
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return h.version
+	}, notify)
+}
+
+// fetch requests the document and updates the values if changed.
+func (h *HTTPValueProvider) fetch() (err error) {
+	defer func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.err = err
+	}()
+
+	req, err := http.NewRequest(http.MethodGet, h.url, nil)
+	if err != nil {
+		return err
+	}
+	for key, values := range h.header {
+		req.Header[key] = values
+	}
+	h.mu.Lock()
+	if h.etag != "" && h.values != nil {
+		req.Header.Set("If-None-Match", h.etag)
+	}
+	h.mu.Unlock()
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch %s failed, status=%s", h.url, resp.Status)
+	}
+	// one more byte is read to detect the larger documents
+	content, err := io.ReadAll(io.LimitReader(resp.Body, h.maxBodySize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(content)) > h.maxBodySize {
+		return fmt.Errorf("fetch %s failed, the document exceeds %d bytes", h.url, h.maxBodySize)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	decoder, err := h.decoder(contentType)
+	if err != nil {
+		return err
+	}
+	values, err := decoder(content)
+	if err != nil {
+		return fmt.Errorf("decode %s failed, err=%w", h.url, err)
+	}
+
+	h.mu.Lock()
+	h.etag = resp.Header.Get("ETag")
+	h.values = values
+	h.version = contentVersion(content)
+	h.mu.Unlock()
+
+	if h.cacheFile != "" {
+		cache, err := json.Marshal(httpCache{ContentType: contentType, Document: string(content)})
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(h.cacheFile, cache, 0o600)
+		if err != nil {
+			return fmt.Errorf("write cache file %s failed, err=%w", h.cacheFile, err)
+		}
+	}
+	return nil
+}
+
+// decoder returns the decoder of the content type, or of the extension of the URL path, or the JSON decoder.
+func (h *HTTPValueProvider) decoder(contentType string) (ValueDecoder, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.Contains(mediaType, "yaml"):
+		return getValueDecoder(".yaml")
+	case strings.Contains(mediaType, "json"):
+		return getValueDecoder(".json")
+	}
+	if u, err := url.Parse(h.url); err == nil {
+		if decoder, err := getValueDecoder(u.Path); err == nil {
+			return decoder, nil
+		}
+	}
+	return getValueDecoder(".json")
+}
+
+func contentVersion(content []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(content))
+}
+
+// pollFingerprint calls notify when the fingerprint changes from last, the fingerprint of the provided values.
+// It is polled in the background right away and then at the interval until stop is called.
+func pollFingerprint(interval time.Duration, last string, fingerprint func() string, notify func()) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if current := fingerprint(); current != last {
+				last = current
+				notify()
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
+
+// fileFingerprint returns the modification times, sizes and symlink targets of the files, the missing files are skipped.
+func fileFingerprint(paths ...string) string {
+	var sb strings.Builder
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		target, _ := os.Readlink(path)
+		_, _ = fmt.Fprintf(&sb, "%s:%d:%d:%s;", path, info.ModTime().UnixNano(), info.Size(), target)
+	}
+	return sb.String()
+}